@@ -1,13 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/netip"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/sharukh010/social/internal/auth"
+	"github.com/sharukh010/social/internal/mailer"
+	"github.com/sharukh010/social/internal/ratelimiter"
 	"github.com/sharukh010/social/internal/store"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/sharukh010/social/docs"
@@ -15,17 +27,42 @@ import (
 )
 
 type application struct {
-	config config
-	store  store.Storage
-	logger *zap.SugaredLogger
+	config        config
+	store         store.Storage
+	logger        *zap.SugaredLogger
+	authenticator auth.Authenticator
+	mailer        mailer.Client
+	rateLimiter   rateLimiters
+	metrics       *metrics
+	tracer        trace.Tracer
+	wg            sync.WaitGroup
+	// draining is set once shutdown starts so the health check reports
+	// the instance as not ready
+	draining atomic.Bool
 }
 
 type config struct {
-	addr   string
-	apiURL string
-	db     dbConfig
-	mail   mailConfig
-	env    string
+	addr            string
+	shutdownTimeout time.Duration
+	shutdownDelay   time.Duration
+	apiURL          string
+	frontendURL     string
+	db              dbConfig
+	mail            mailConfig
+	auth            authConfig
+	pagination      paginationConfig
+	comments        commentsConfig
+	posts           postsConfig
+	trash           trashConfig
+	users           usersConfig
+	redis           redisConfig
+	cache           cacheConfig
+	rateLimiter     rateLimiterConfig
+	tracing         tracingConfig
+	logLevel        string
+	env             string
+	// trustedProxies are the peers whose forwarding headers name the client
+	trustedProxies []netip.Prefix
 }
 
 type dbConfig struct {
@@ -36,24 +73,137 @@ type dbConfig struct {
 }
 
 type mailConfig struct {
-	exp time.Duration
+	exp       time.Duration
+	resetExp  time.Duration
+	driver    string
+	fromEmail string
+	smtp      smtpConfig
+	sandbox   sandboxConfig
+}
+
+type smtpConfig struct {
+	host     string
+	port     int
+	username string
+	password string
+}
+
+type sandboxConfig struct {
+	// file the sandbox mailer appends to, stdout when empty
+	file string
+}
+
+type redisConfig struct {
+	addr    string
+	pw      string
+	db      int
+	enabled bool
+}
+
+type cacheConfig struct {
+	// enabled caches users and posts in redis, otherwise nothing is cached
+	enabled bool
+	ttl     time.Duration
+}
+
+type rateLimiterConfig struct {
+	// backend is "memory" or "redis"
+	backend string
+	// algorithm is "fixed" for fixed windows or "token" for token buckets
+	algorithm string
+	// global limits every client by IP
+	global ratelimiter.Config
+	// auth limits the public authentication routes by IP
+	auth ratelimiter.Config
+	// user limits authenticated routes by user
+	user ratelimiter.Config
+}
+
+type tracingConfig struct {
+	// enabled exports spans, otherwise they are dropped
+	enabled bool
+	// exporter is "stdout" or "otlp"
+	exporter string
+	// endpoint is the host:port of the OTLP/HTTP collector
+	endpoint    string
+	serviceName string
+}
+
+// rateLimiters holds one limiter per policy, nil when the policy is disabled.
+type rateLimiters struct {
+	global ratelimiter.Limiter
+	auth   ratelimiter.Limiter
+	user   ratelimiter.Limiter
+}
+
+type postsConfig struct {
+	// requireIfMatch rejects updates and deletes of posts without an
+	// If-Match header, which is otherwise only checked when sent
+	requireIfMatch bool
+}
+
+type trashConfig struct {
+	// retention is how long deleted posts and comments can be restored
+	// before they are purged for good
+	retention time.Duration
+	// purgeInterval is how often the trash is purged, never when zero
+	purgeInterval time.Duration
+}
+
+type usersConfig struct {
+	// usernameCooldown is how long users wait between username changes
+	usernameCooldown time.Duration
+}
+
+type commentsConfig struct {
+	maxDepth int
+}
+
+type paginationConfig struct {
+	cursorSecret string
+}
+
+type authConfig struct {
+	basic basicConfig
+	token tokenConfig
+}
+
+// basicConfig holds the credentials of the operator endpoints, such as the
+// metrics. An empty password locks them.
+type basicConfig struct {
+	user string
+	pass string
+}
+
+type tokenConfig struct {
+	secret     string
+	exp        time.Duration
+	refreshExp time.Duration
+	aud        string
+	iss        string
 }
 
 func (app *application) mount() http.Handler {
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
+	r.Use(app.RealIPMiddleware)
+	r.Use(app.TracingMiddleware)
+	r.Use(app.LoggerMiddleware)
+	r.Use(app.MetricsMiddleware)
 	r.Use(middleware.Recoverer)
+	r.Use(app.RateLimiterMiddleware(app.rateLimiter.global, clientIPKey))
 
 	// Set a timeout value on the request context (ctx), that will signal
 	// through ctx.Done() that the request has timed out and further
 	// processing should be stopped.
 	r.Use(middleware.Timeout(60 * time.Second))
 
+	userRateLimit := app.RateLimiterMiddleware(app.rateLimiter.user, authUserKey)
+
 	r.Route("/v1", func(r chi.Router) {
 		r.Get("/health", app.healthCheckHandler)
+		r.With(app.BasicAuthMiddleware).Get("/debug/metrics", app.metrics.handler().ServeHTTP)
 
 		docsURL := fmt.Sprintf("%s/v1/swagger/doc.json", app.config.addr)
 		r.Get("/swagger/*", httpSwagger.Handler(
@@ -61,28 +211,72 @@ func (app *application) mount() http.Handler {
 		))
 
 		r.Route("/posts", func(r chi.Router) {
+			r.Use(app.AuthTokenMiddleware, userRateLimit)
 			r.Post("/", app.createPostHandler)
+			r.With(app.trashedPostsContextMiddleware).Post("/{postID}/restore", app.checkPostOwnership("admin", app.restorePostHandler))
 
 			r.Route("/{postID}", func(r chi.Router) {
 				r.Use(app.postsContextMiddleware)
 
 				r.Get("/", app.getPostHandler)
 
-				r.Patch("/", app.updatePostHandler)
+				r.Patch("/", app.checkPostOwnership("moderator", app.updatePostHandler))
+
+				r.Delete("/", app.checkPostOwnership("admin", app.deletePostHandler))
 
-				r.Delete("/", app.deletePostHandler)
+				r.Route("/revisions", func(r chi.Router) {
+					r.Get("/", app.getPostRevisionsHandler)
+					r.Get("/diff", app.diffPostRevisionsHandler)
+					r.Get("/{version}", app.getPostRevisionHandler)
+					r.Post("/{version}/restore", app.restorePostRevisionHandler)
+				})
+
+				r.Route("/reactions/{kind}", func(r chi.Router) {
+					r.Get("/", app.getPostReactionsHandler)
+					r.Put("/", app.reactToPostHandler)
+					r.Delete("/", app.removePostReactionHandler)
+				})
 
 				r.Route("/comments", func(r chi.Router) {
+					r.Get("/", app.getCommentsHandler)
 					r.Post("/", app.createCommentHandler)
-					r.Delete("/{commentID}", app.deleteCommentHandler)
+
+					r.With(app.trashedCommentsContextMiddleware).Post("/{commentID}/restore", app.checkCommentOwnership("moderator", app.restoreCommentHandler))
+
+					r.Route("/{commentID}", func(r chi.Router) {
+						r.Use(app.commentsContextMiddleware)
+
+						r.Delete("/", app.checkCommentOwnership("moderator", app.deleteCommentHandler))
+
+						r.Get("/replies", app.getRepliesHandler)
+						r.Post("/replies", app.createReplyHandler)
+					})
 				})
 
 			})
 		})
 
+		r.With(app.AuthTokenMiddleware, userRateLimit).Get("/search", app.searchHandler)
+
 		r.Route("/users", func(r chi.Router) {
 			r.Put("/activate/{token}", app.activateUserHandler)
+
+			r.Route("/me", func(r chi.Router) {
+				r.Use(app.AuthTokenMiddleware, userRateLimit)
+
+				r.Patch("/", app.updateUserProfileHandler)
+
+				r.Get("/sessions", app.getUserSessionsHandler)
+				r.Delete("/sessions/{sessionID}", app.revokeUserSessionHandler)
+
+				r.Put("/privacy", app.updatePrivacyHandler)
+				r.Get("/follow-requests", app.getFollowRequestsHandler)
+				r.Put("/follow-requests/{userID}/approve", app.approveFollowRequestHandler)
+				r.Put("/follow-requests/{userID}/reject", app.rejectFollowRequestHandler)
+			})
+
 			r.Route("/{userID}", func(r chi.Router) {
+				r.Use(app.AuthTokenMiddleware, userRateLimit)
 				r.Use(app.userContextMiddleware)
 
 				r.Get("/", app.getUserHandler)
@@ -90,21 +284,56 @@ func (app *application) mount() http.Handler {
 				r.Put("/follow", app.followUserHandler)
 				r.Put("/unfollow", app.unfollowUserHandler)
 
+				r.Get("/followers", app.getUserFollowersHandler)
+				r.Get("/following", app.getUserFollowingHandler)
+				r.Get("/mutuals", app.getUserMutualsHandler)
+
+				r.Put("/block", app.blockUserHandler)
+				r.Delete("/block", app.unblockUserHandler)
+				r.Put("/mute", app.muteUserHandler)
+				r.Delete("/mute", app.unmuteUserHandler)
+
 			})
 			r.Group(func(r chi.Router) {
+				r.Use(app.AuthTokenMiddleware, userRateLimit)
 				r.Get("/feed", app.getUserFeedHandler)
 			})
 		})
 
 		//public routes
 		r.Route("/authenticate", func(r chi.Router) {
+			r.Use(app.RateLimiterMiddleware(app.rateLimiter.auth, clientIPKey))
+
 			r.Post("/user", app.registerUserHandler)
+			r.Post("/token", app.createTokenHandler)
+			r.Post("/refresh", app.refreshTokenHandler)
+			r.Post("/logout", app.logoutHandler)
+			r.Post("/password/forgot", app.forgotPasswordHandler)
+			r.Post("/password/reset", app.resetPasswordHandler)
 		})
 
 	})
 
 	return r
 }
+
+// background runs fn in its own goroutine, tracked so that shutdown can wait
+// for it, and logs instead of crashing the server if fn panics.
+func (app *application) background(fn func()) {
+	app.wg.Add(1)
+
+	go func() {
+		defer app.wg.Done()
+		defer func() {
+			if err := recover(); err != nil {
+				app.logger.Errorw("background task panicked", "error", err)
+			}
+		}()
+
+		fn()
+	}()
+}
+
 func (app *application) run(mux http.Handler) error {
 	docs.SwaggerInfo.Version = version
 	docs.SwaggerInfo.Host = app.config.apiURL
@@ -117,6 +346,75 @@ func (app *application) run(mux http.Handler) error {
 		ReadTimeout:  time.Second * 10,
 		IdleTimeout:  time.Minute,
 	}
+
+	shutdown := make(chan error)
+
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	app.background(func() {
+		app.purgeTrash(purgeCtx)
+	})
+
+	go func() {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		s := <-quit
+
+		app.logger.Infow("signal caught, draining", "signal", s.String(), "delay", app.config.shutdownDelay.String(), "timeout", app.config.shutdownTimeout.String())
+		shutdown <- app.shutdown(&srv, stopPurge)
+	}()
+
 	app.logger.Infow("Server has started", "addr", app.config.addr, "env", app.config.env)
-	return srv.ListenAndServe()
+
+	err := srv.ListenAndServe()
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	if err := <-shutdown; err != nil {
+		return err
+	}
+
+	app.logger.Infow("server has stopped", "addr", app.config.addr, "env", app.config.env)
+
+	return nil
+}
+
+// shutdown marks the instance as draining and keeps serving for the
+// configured delay, so that load balancers polling the health check stop
+// routing to it before srv stops accepting connections. It then waits for
+// in-flight requests and, after calling stopBackground, for background jobs.
+func (app *application) shutdown(srv *http.Server, stopBackground func()) error {
+	app.draining.Store(true)
+	time.Sleep(app.config.shutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), app.config.shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		return err
+	}
+	app.logger.Infow("in-flight requests completed")
+
+	stopBackground()
+
+	return app.waitForBackground(ctx)
+}
+
+// waitForBackground blocks until every task started with background has
+// returned, or ctx is done.
+func (app *application) waitForBackground(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		app.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		app.logger.Infow("background tasks completed")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for background tasks: %w", ctx.Err())
+	}
 }
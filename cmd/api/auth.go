@@ -1,23 +1,48 @@
 package main
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/sharukh010/social/internal/mailer"
 	"github.com/sharukh010/social/internal/store"
 )
 
 type RegisterUserPayload struct {
-	UserName string `json:"username" validate:"required,max=100"`
+	UserName string `json:"username" validate:"required,max=100,username"`
 	Email    string `json:"email" validate:"required,email,max=255"`
 	Password string `json:"password" validate:"required,min=3,max=72"`
 }
 
-type UserWithToken struct {
-	*store.User
-	Token string `json:"token"`
+type CreateUserTokenPayload struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required,min=3,max=72"`
+}
+
+type RefreshTokenPayload struct {
+	RefreshToken string `json:"refresh_token" validate:"required,max=255"`
+}
+
+type ForgotPasswordPayload struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+type ResetPasswordPayload struct {
+	Token    string `json:"token" validate:"required,max=255"`
+	Password string `json:"password" validate:"required,min=3,max=72"`
+}
+
+type UserTokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 // RegisterUser godoc
@@ -28,9 +53,9 @@ type UserWithToken struct {
 //	@Accept			json
 //	@Produce		json
 //	@Param			post	body		RegisterUserPayload	true	"User credentials"
-//	@Success		201		{object}	UserWithToken		"User Registered"
-//	@Failure		400		{object}	error				"Invalid User Payload"
-//	@Failure		500		{object}	error				"Something went wrong"
+//	@Success		201		{object}	store.User			"User Registered"
+//	@Failure		400		{object}	problem				"Invalid User Payload"
+//	@Failure		500		{object}	problem				"Something went wrong"
 //	@Security		ApiKeyAuth
 //	@Router			/authenticate/user [post]
 func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -58,21 +83,382 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 
 	plainToken := uuid.New().String()
 
-	hash := sha256.Sum256([]byte(plainToken))
-	hashToken := hex.EncodeToString(hash[:])
+	if err := app.store.Users.CreateAndInvite(ctx, user, hashToken(plainToken), app.config.mail.exp); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	activationURL := fmt.Sprintf("%s/confirm/%s", app.config.frontendURL, plainToken)
+
+	vars := struct {
+		Username      string
+		ActivationURL string
+		Token         string
+	}{
+		Username:      user.Username,
+		ActivationURL: activationURL,
+		Token:         plainToken,
+	}
+
+	if err := app.mailer.Send(mailer.UserWelcomeTemplate, user.Username, user.Email, vars); err != nil {
+		app.requestLogger(r).Errorw("error sending welcome email", "error", err)
+
+		// rollback user creation if email fails (SAGA pattern)
+		if err := app.store.Users.Delete(ctx, user.ID); err != nil {
+			app.requestLogger(r).Errorw("error deleting user", "error", err)
+		}
+
+		app.internalServerError(w, r, err)
+		return
+	}
 
-	if err := app.store.Users.CreateAndInvite(ctx, user, hashToken, app.config.mail.exp); err != nil {
+	if err := app.jsonResponse(w, http.StatusCreated, user); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
+}
+
+// CreateToken godoc
+//
+//	@Summary		Creates a token
+//	@Description	Creates an access and refresh token pair for an active user
+//	@Tags			authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		CreateUserTokenPayload	true	"User credentials"
+//	@Success		201		{object}	UserTokens				"Tokens"
+//	@Failure		400		{object}	problem					"Invalid Token Payload"
+//	@Failure		401		{object}	problem					"Invalid Credentials"
+//	@Failure		500		{object}	problem					"Something went wrong"
+//	@Router			/authenticate/token [post]
+func (app *application) createTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var payload CreateUserTokenPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
 
-	userWithToken := UserWithToken{
-		User:  user,
-		Token: plainToken,
+	user, err := app.store.Users.GetByEmail(r.Context(), payload.Email)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.unauthorizedErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
 	}
-	// store the user
-	if err := app.jsonResponse(w, http.StatusCreated, userWithToken); err != nil {
+
+	if err := user.Password.Compare(payload.Password); err != nil {
+		app.unauthorizedErrorResponse(w, r, err)
+		return
+	}
+
+	tokens, err := app.issueTokens(r, user, nil)
+	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, tokens); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// RefreshToken godoc
+//
+//	@Summary		Refreshes a token
+//	@Description	Exchanges a refresh token for a new access and refresh token pair
+//	@Tags			authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		RefreshTokenPayload	true	"Refresh token"
+//	@Success		201		{object}	UserTokens			"Tokens"
+//	@Failure		400		{object}	problem				"Invalid Refresh Payload"
+//	@Failure		401		{object}	problem				"Invalid or revoked refresh token"
+//	@Failure		500		{object}	problem				"Something went wrong"
+//	@Router			/authenticate/refresh [post]
+func (app *application) refreshTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var payload RefreshTokenPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	session, err := app.store.Sessions.GetByToken(ctx, hashToken(payload.RefreshToken))
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.unauthorizedErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	// a revoked token being presented again means it was stolen or replayed,
+	// so every session descending from the same login is revoked
+	if session.RevokedAt != nil {
+		app.revokeSessionFamily(w, r, session)
+		return
+	}
+
+	if time.Now().After(session.Expiry) {
+		app.unauthorizedErrorResponse(w, r, errors.New("refresh token has expired"))
+		return
+	}
+
+	user, err := app.store.Users.GetByID(ctx, session.UserID)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.unauthorizedErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	tokens, err := app.issueTokens(r, user, session)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.revokeSessionFamily(w, r, session)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, tokens); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// Logout godoc
+//
+//	@Summary		Logs out
+//	@Description	Revokes the session the refresh token belongs to
+//	@Tags			authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		RefreshTokenPayload	true	"Refresh token"
+//	@Success		204		{object}	nil					"Logged out"
+//	@Failure		400		{object}	problem				"Invalid Logout Payload"
+//	@Failure		401		{object}	problem				"Invalid refresh token"
+//	@Failure		500		{object}	problem				"Something went wrong"
+//	@Router			/authenticate/logout [post]
+func (app *application) logoutHandler(w http.ResponseWriter, r *http.Request) {
+	var payload RefreshTokenPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	session, err := app.store.Sessions.GetByToken(ctx, hashToken(payload.RefreshToken))
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.unauthorizedErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.store.Sessions.RevokeFamily(ctx, session.UserID, session.FamilyID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ForgotPassword godoc
+//
+//	@Summary		Request a password reset
+//	@Description	Emails a single-use password reset link if the address belongs to an active user
+//	@Tags			authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		ForgotPasswordPayload	true	"User email"
+//	@Success		202		{object}	nil						"Reset requested"
+//	@Failure		400		{object}	problem					"Invalid Payload"
+//	@Failure		500		{object}	problem					"Something went wrong"
+//	@Router			/authenticate/password/forgot [post]
+func (app *application) forgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var payload ForgotPasswordPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// the response is the same whether or not the email is known, and the
+	// email is sent in the background so timing doesn't give it away either
+	user, err := app.store.Users.GetByEmail(r.Context(), payload.Email)
+	switch err {
+	case nil:
+		app.background(func() {
+			app.sendPasswordReset(user)
+		})
+	case store.ErrNotFound:
+	default:
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (app *application) sendPasswordReset(user *store.User) {
+	ctx := context.Background()
+
+	plainToken := uuid.New().String()
+
+	if err := app.store.Users.CreatePasswordReset(ctx, user.ID, hashToken(plainToken), app.config.mail.resetExp); err != nil {
+		app.logger.Errorw("error creating password reset", "user_id", user.ID, "error", err)
+		return
+	}
+
+	vars := struct {
+		Username string
+		ResetURL string
+		Expiry   string
+	}{
+		Username: user.Username,
+		ResetURL: fmt.Sprintf("%s/password/reset/%s", app.config.frontendURL, plainToken),
+		Expiry:   app.config.mail.resetExp.String(),
+	}
+
+	if err := app.mailer.Send(mailer.PasswordResetTemplate, user.Username, user.Email, vars); err != nil {
+		app.logger.Errorw("error sending password reset email", "user_id", user.ID, "error", err)
+	}
+}
+
+// ResetPassword godoc
+//
+//	@Summary		Reset a password
+//	@Description	Sets a new password using a reset token and signs the user out of every session
+//	@Tags			authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		ResetPasswordPayload	true	"Reset token and new password"
+//	@Success		204		{object}	nil						"Password Reset"
+//	@Failure		400		{object}	problem					"Invalid Payload"
+//	@Failure		404		{object}	problem					"Invalid or expired token"
+//	@Failure		500		{object}	problem					"Something went wrong"
+//	@Router			/authenticate/password/reset [post]
+func (app *application) resetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var payload ResetPasswordPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	var password store.Password
+	if err := password.Set(payload.Password); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if _, err := app.store.Users.ResetPassword(r.Context(), payload.Token, password); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// issueTokens stores a new refresh token for user and signs a new access
+// token, which names the session family in its sid claim so it stops working
+// once the family is revoked. When current is nil a new session family is
+// started, otherwise current is rotated out in favour of the new refresh
+// token.
+func (app *application) issueTokens(r *http.Request, user *store.User, current *store.Session) (*UserTokens, error) {
+	now := time.Now()
+
+	refreshToken := uuid.New().String()
+	session := &store.Session{
+		UserID:    user.ID,
+		Token:     hashToken(refreshToken),
+		UserAgent: r.UserAgent(),
+		IP:        r.RemoteAddr,
+		Expiry:    now.Add(app.config.auth.token.refreshExp),
+	}
+
+	ctx := r.Context()
+	var err error
+	if current == nil {
+		session.FamilyID = uuid.New().String()
+		err = app.store.Sessions.Create(ctx, session)
+	} else {
+		err = app.store.Sessions.Rotate(ctx, current, session)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{
+		"sub": strconv.FormatInt(user.ID, 10),
+		"sid": session.FamilyID,
+		"exp": now.Add(app.config.auth.token.exp).Unix(),
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"iss": app.config.auth.token.iss,
+		"aud": app.config.auth.token.aud,
+	}
+	token, err := app.authenticator.GenerateToken(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserTokens{
+		Token:        token,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func (app *application) revokeSessionFamily(w http.ResponseWriter, r *http.Request, session *store.Session) {
+	if err := app.store.Sessions.RevokeFamily(r.Context(), session.UserID, session.FamilyID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.requestLogger(r).Warnw("refresh token reuse detected", "user_id", session.UserID, "session_id", session.ID)
+	app.unauthorizedErrorResponse(w, r, errors.New("refresh token has been revoked"))
+}
+
+func hashToken(plainToken string) string {
+	hash := sha256.Sum256([]byte(plainToken))
+	return hex.EncodeToString(hash[:])
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRegisterAndAuthenticate(t *testing.T) {
+	app, mailbox := newTestApplication(t)
+	mux := app.mount()
+
+	req := newTestRequest(t, app, http.MethodPost, "/v1/authenticate/user", nil, RegisterUserPayload{
+		UserName: "alice",
+		Email:    "alice@example.com",
+		Password: "password",
+	})
+	checkResponseCode(t, http.StatusCreated, executeRequest(req, mux))
+
+	t.Run("should reject usernames that can't be mentioned", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodPost, "/v1/authenticate/user", nil, RegisterUserPayload{
+			UserName: "bob smith",
+			Email:    "bob@example.com",
+			Password: "password",
+		})
+		checkResponseCode(t, http.StatusBadRequest, executeRequest(req, mux))
+	})
+
+	credentials := CreateUserTokenPayload{Email: "alice@example.com", Password: "password"}
+
+	t.Run("should not authenticate before activation", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodPost, "/v1/authenticate/token", nil, credentials)
+		checkResponseCode(t, http.StatusUnauthorized, executeRequest(req, mux))
+	})
+
+	req = newTestRequest(t, app, http.MethodPut, "/v1/users/activate/"+mailbox.lastToken(t), nil, nil)
+	checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+
+	req = newTestRequest(t, app, http.MethodPost, "/v1/authenticate/token", nil, credentials)
+	rr := executeRequest(req, mux)
+	checkResponseCode(t, http.StatusCreated, rr)
+
+	var tokens UserTokens
+	decodeData(t, rr, &tokens)
+
+	t.Run("should rotate the refresh token", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodPost, "/v1/authenticate/refresh", nil, RefreshTokenPayload{RefreshToken: tokens.RefreshToken})
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusCreated, rr)
+
+		var rotated UserTokens
+		decodeData(t, rr, &rotated)
+
+		// reusing the old refresh token revokes the whole family
+		req = newTestRequest(t, app, http.MethodPost, "/v1/authenticate/refresh", nil, RefreshTokenPayload{RefreshToken: tokens.RefreshToken})
+		checkResponseCode(t, http.StatusUnauthorized, executeRequest(req, mux))
+
+		req = newTestRequest(t, app, http.MethodPost, "/v1/authenticate/refresh", nil, RefreshTokenPayload{RefreshToken: rotated.RefreshToken})
+		checkResponseCode(t, http.StatusUnauthorized, executeRequest(req, mux))
+	})
+
+	login := func(t *testing.T) UserTokens {
+		t.Helper()
+
+		req := newTestRequest(t, app, http.MethodPost, "/v1/authenticate/token", nil, credentials)
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusCreated, rr)
+
+		var tokens UserTokens
+		decodeData(t, rr, &tokens)
+		return tokens
+	}
+	getFeed := func(t *testing.T, tokens UserTokens, code int) {
+		t.Helper()
+
+		req := newTestRequest(t, app, http.MethodGet, "/v1/users/feed", nil, nil)
+		req.Header.Set("Authorization", "Bearer "+tokens.Token)
+		checkResponseCode(t, code, executeRequest(req, mux))
+	}
+
+	t.Run("should reject access tokens once logged out", func(t *testing.T) {
+		tokens := login(t)
+		other := login(t)
+		getFeed(t, tokens, http.StatusOK)
+
+		req := newTestRequest(t, app, http.MethodPost, "/v1/authenticate/logout", nil, RefreshTokenPayload{RefreshToken: tokens.RefreshToken})
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+
+		getFeed(t, tokens, http.StatusUnauthorized)
+		getFeed(t, other, http.StatusOK)
+	})
+
+	t.Run("should reject access tokens after a password reset", func(t *testing.T) {
+		tokens := login(t)
+
+		req := newTestRequest(t, app, http.MethodPost, "/v1/authenticate/password/forgot", nil, ForgotPasswordPayload{Email: credentials.Email})
+		checkResponseCode(t, http.StatusAccepted, executeRequest(req, mux))
+		app.wg.Wait()
+
+		req = newTestRequest(t, app, http.MethodPost, "/v1/authenticate/password/reset", nil, ResetPasswordPayload{
+			Token:    mailbox.lastToken(t),
+			Password: "new password",
+		})
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+
+		getFeed(t, tokens, http.StatusUnauthorized)
+	})
+}
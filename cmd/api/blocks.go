@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+// BlockUser godoc
+//
+//	@Summary		Block a user
+//	@Description	Block a user, removing the follows between the two users and hiding their posts from each other
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path		int		true	"User ID"
+//	@Success		204		{object}	nil		"User Blocked"
+//	@Failure		400		{object}	problem	"Cannot block yourself"
+//	@Failure		404		{object}	problem	"User not found"
+//	@Failure		500		{object}	problem	"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/block [put]
+func (app *application) blockUserHandler(w http.ResponseWriter, r *http.Request) {
+	app.updateUserPair(w, r, "block", app.store.Blocks.Block)
+}
+
+// UnblockUser godoc
+//
+//	@Summary		Unblock a user
+//	@Description	Unblock a user, without restoring the follows removed by the block
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path		int		true	"User ID"
+//	@Success		204		{object}	nil		"User Unblocked"
+//	@Failure		404		{object}	problem	"User not found or not blocked"
+//	@Failure		500		{object}	problem	"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/block [delete]
+func (app *application) unblockUserHandler(w http.ResponseWriter, r *http.Request) {
+	app.updateUserPair(w, r, "unblock", app.store.Blocks.Unblock)
+}
+
+// MuteUser godoc
+//
+//	@Summary		Mute a user
+//	@Description	Mute a user, leaving their posts out of your feed
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path		int		true	"User ID"
+//	@Success		204		{object}	nil		"User Muted"
+//	@Failure		400		{object}	problem	"Cannot mute yourself"
+//	@Failure		404		{object}	problem	"User not found"
+//	@Failure		500		{object}	problem	"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/mute [put]
+func (app *application) muteUserHandler(w http.ResponseWriter, r *http.Request) {
+	app.updateUserPair(w, r, "mute", app.store.Blocks.Mute)
+}
+
+// UnmuteUser godoc
+//
+//	@Summary		Unmute a user
+//	@Description	Unmute a user, bringing their posts back to your feed
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path		int		true	"User ID"
+//	@Success		204		{object}	nil		"User Unmuted"
+//	@Failure		404		{object}	problem	"User not found or not muted"
+//	@Failure		500		{object}	problem	"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/mute [delete]
+func (app *application) unmuteUserHandler(w http.ResponseWriter, r *http.Request) {
+	app.updateUserPair(w, r, "unmute", app.store.Blocks.Unmute)
+}
+
+// updateUserPair applies update, such as a block, from the authenticated
+// user to the user in the URL.
+func (app *application) updateUserPair(w http.ResponseWriter, r *http.Request, action string, update func(context.Context, int64, int64) error) {
+	authUser := getAuthUserFromCtx(r)
+	user := getUserFromCtx(r)
+
+	if authUser.ID == user.ID {
+		app.badRequestResponse(w, r, errors.New("you cannot "+action+" yourself"))
+		return
+	}
+
+	if err := update(r.Context(), authUser.ID, user.ID); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+func TestBlockAndMute(t *testing.T) {
+	app, _ := newTestApplication(t)
+	mux := app.mount()
+
+	alice := newTestUser(t, app, "alice", "user")
+	bob := newTestUser(t, app, "bob", "user")
+	carol := newTestUser(t, app, "carol", "user")
+
+	userURL := func(user *store.User, path string) string {
+		return fmt.Sprintf("/v1/users/%d/%s", user.ID, path)
+	}
+	createPost := func(t *testing.T, user *store.User) string {
+		t.Helper()
+
+		req := newTestRequest(t, app, http.MethodPost, "/v1/posts/", user, CreatePostPayload{Title: "Hello", Content: "World"})
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusCreated, rr)
+
+		var post store.Post
+		decodeData(t, rr, &post)
+		return fmt.Sprintf("/v1/posts/%d", post.ID)
+	}
+	feedAuthors := func(t *testing.T) map[int64]bool {
+		t.Helper()
+
+		req := newTestRequest(t, app, http.MethodGet, "/v1/users/feed", alice, nil)
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr)
+
+		var feed []store.PostWithMetadata
+		decodeData(t, rr, &feed)
+		authors := map[int64]bool{}
+		for _, p := range feed {
+			authors[p.UserID] = true
+		}
+		return authors
+	}
+
+	for _, f := range []struct{ follower, followed *store.User }{
+		{alice, bob},
+		{bob, alice},
+		{alice, carol},
+	} {
+		req := newTestRequest(t, app, http.MethodPut, userURL(f.followed, "follow"), f.follower, nil)
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+	}
+
+	bobPost := createPost(t, bob)
+	carolPost := createPost(t, carol)
+
+	t.Run("should not allow blocking yourself", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodPut, userURL(alice, "block"), alice, nil)
+		checkResponseCode(t, http.StatusBadRequest, executeRequest(req, mux))
+	})
+
+	t.Run("should cut off blocked users", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodPut, userURL(bob, "block"), alice, nil)
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+
+		stats, err := app.store.Followers.GetStats(req.Context(), alice.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *stats != (store.FollowStats{Followers: 0, Following: 1}) {
+			t.Fatalf("expected the follows with bob to be removed, got %+v", stats)
+		}
+
+		req = newTestRequest(t, app, http.MethodPut, userURL(alice, "follow"), bob, nil)
+		checkResponseCode(t, http.StatusForbidden, executeRequest(req, mux))
+
+		req = newTestRequest(t, app, http.MethodGet, bobPost, alice, nil)
+		checkResponseCode(t, http.StatusNotFound, executeRequest(req, mux))
+
+		if feedAuthors(t)[bob.ID] {
+			t.Fatal("expected bob's posts to be left out of the feed")
+		}
+
+		req = newTestRequest(t, app, http.MethodGet, "/v1/search?q=world", alice, nil)
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr)
+
+		var results []store.PostSearchResult
+		decodeData(t, rr, &results)
+		for _, res := range results {
+			if res.UserID == bob.ID {
+				t.Fatal("expected bob's posts to be left out of the search results")
+			}
+		}
+	})
+
+	t.Run("should not allow replying to a blocked user", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodPost, carolPost+"/comments", bob, CreateCommentPayload{Content: "Hello carol"})
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusCreated, rr)
+
+		var comment store.Comment
+		decodeData(t, rr, &comment)
+
+		url := fmt.Sprintf("%s/comments/%d/replies", carolPost, comment.ID)
+		req = newTestRequest(t, app, http.MethodPost, url, alice, CreateCommentPayload{Content: "Hello bob"})
+		checkResponseCode(t, http.StatusForbidden, executeRequest(req, mux))
+	})
+
+	t.Run("should hide the comments of blocked users", func(t *testing.T) {
+		commenters := func(t *testing.T, user *store.User) map[int64]bool {
+			t.Helper()
+
+			req := newTestRequest(t, app, http.MethodGet, carolPost+"/comments", user, nil)
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, http.StatusOK, rr)
+
+			var comments []store.Comment
+			decodeData(t, rr, &comments)
+
+			req = newTestRequest(t, app, http.MethodGet, carolPost, user, nil)
+			rr = executeRequest(req, mux)
+			checkResponseCode(t, http.StatusOK, rr)
+
+			var post store.Post
+			decodeData(t, rr, &post)
+
+			ids := map[int64]bool{}
+			for _, c := range append(comments, post.Comments...) {
+				ids[c.UserID] = true
+			}
+			return ids
+		}
+
+		if commenters(t, alice)[bob.ID] {
+			t.Fatal("expected bob's comments to be hidden from alice")
+		}
+		if !commenters(t, carol)[bob.ID] {
+			t.Fatal("expected bob's comments to be shown to carol")
+		}
+	})
+
+	t.Run("should unblock a user", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodDelete, userURL(bob, "block"), alice, nil)
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+
+		req = newTestRequest(t, app, http.MethodDelete, userURL(bob, "block"), alice, nil)
+		checkResponseCode(t, http.StatusNotFound, executeRequest(req, mux))
+
+		req = newTestRequest(t, app, http.MethodGet, bobPost, alice, nil)
+		checkResponseCode(t, http.StatusOK, executeRequest(req, mux))
+	})
+
+	t.Run("should leave muted users out of the feed", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodPut, userURL(carol, "mute"), alice, nil)
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+
+		if feedAuthors(t)[carol.ID] {
+			t.Fatal("expected carol's posts to be left out of the feed")
+		}
+
+		req = newTestRequest(t, app, http.MethodGet, carolPost, alice, nil)
+		checkResponseCode(t, http.StatusOK, executeRequest(req, mux))
+
+		req = newTestRequest(t, app, http.MethodDelete, userURL(carol, "mute"), alice, nil)
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+
+		if !feedAuthors(t)[carol.ID] {
+			t.Fatal("expected carol's posts back in the feed")
+		}
+	})
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -8,8 +11,13 @@ import (
 	"github.com/sharukh010/social/internal/store"
 )
 
+type commentKey string
+
+const commentCtx commentKey = "comment"
+
+const commentURLParam = "commentID"
+
 type CreateCommentPayload struct {
-	UserID  int64  `json:"user_id" validate:"required"`
 	Content string `json:"content" validate:"required,min=6,max=100"`
 }
 
@@ -23,16 +31,17 @@ type CreateCommentPayload struct {
 //	@Param			id		path		int						true	"Post ID"
 //	@Param			comment	body		CreateCommentPayload	true	"Comment"
 //	@Success		201		{object}	store.Comment			"Comment Created"
-//	@Failure		400		{object}	error					"Invalid Comment Payload"
-//	@Failure		404		{object}	error					"Post not found"
-//	@Failure		500		{object}	error					"Something Went wrong"
+//	@Failure		400		{object}	problem					"Invalid Comment Payload"
+//	@Failure		403		{object}	problem					"Blocked by the post author"
+//	@Failure		404		{object}	problem					"Post not found"
+//	@Failure		500		{object}	problem					"Something Went wrong"
 //	@Security		ApiKeyAuth
 //	@Router			/posts/{id}/comments [post]
 func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Request) {
 	var payload CreateCommentPayload
 
 	if err := readJSON(w, r, &payload); err != nil {
-		app.internalServerError(w, r, err)
+		app.badRequestResponse(w, r, err)
 		return
 	}
 
@@ -41,19 +50,92 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	post := getPostFromCtx(r)
+	user := getAuthUserFromCtx(r)
 
 	comment := &store.Comment{
 		PostID:  post.ID,
-		UserID:  payload.UserID,
+		UserID:  user.ID,
 		Content: payload.Content,
 	}
 
 	ctx := r.Context()
 
 	if err := app.store.Comments.Create(ctx, comment); err != nil {
+		switch err {
+		case store.ErrBlocked:
+			app.forbiddenResponse(w, r)
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, comment); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
+}
+
+// CreateReply godoc
+//
+//	@Summary		Reply to a Comment
+//	@Description	Creates a reply to a comment and returns its details
+//	@Tags			comments
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID		path		int						true	"Post ID"
+//	@Param			commentID	path		int						true	"Comment ID"
+//	@Param			comment		body		CreateCommentPayload	true	"Reply"
+//	@Success		201			{object}	store.Comment			"Reply Created"
+//	@Failure		400			{object}	problem					"Invalid Reply Payload or thread too deep"
+//	@Failure		403			{object}	problem					"Blocked by the comment author"
+//	@Failure		404			{object}	problem					"Comment not found"
+//	@Failure		500			{object}	problem					"Something Went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/comments/{commentID}/replies [post]
+func (app *application) createReplyHandler(w http.ResponseWriter, r *http.Request) {
+	var payload CreateCommentPayload
+
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	parent := getCommentFromCtx(r)
+	user := getAuthUserFromCtx(r)
+
+	if parent.Depth+1 > app.config.comments.maxDepth {
+		app.badRequestResponse(w, r, fmt.Errorf("replies can be nested at most %d levels deep", app.config.comments.maxDepth))
+		return
+	}
+
+	comment := &store.Comment{
+		PostID:   parent.PostID,
+		UserID:   user.ID,
+		ParentID: &parent.ID,
+		Depth:    parent.Depth + 1,
+		Content:  payload.Content,
+	}
+
+	ctx := r.Context()
+
+	if err := app.store.Comments.Create(ctx, comment); err != nil {
+		switch err {
+		case store.ErrBlocked:
+			app.forbiddenResponse(w, r)
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
 
 	if err := app.jsonResponse(w, http.StatusCreated, comment); err != nil {
 		app.internalServerError(w, r, err)
@@ -61,31 +143,134 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 	}
 }
 
+// GetComments godoc
+//
+//	@Summary		Fetch Comments
+//	@Description	Fetch a page of a post's top-level comments with nested replies
+//	@Tags			comments
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID	path		int				true	"Post ID"
+//	@Param			limit	query		int				false	"Limit"
+//	@Param			sort	query		string			false	"Sort"
+//	@Param			cursor	query		string			false	"Cursor from next_cursor or prev_cursor"
+//	@Param			format	query		string			false	"tree (default) or flat"
+//	@Param			depth	query		int				false	"Levels of replies to include"
+//	@Param			replies	query		int				false	"Replies to include per comment"
+//	@Success		200		{object}	[]store.Comment	"Comments"
+//	@Failure		400		{object}	problem			"Invalid Comment Query"
+//	@Failure		404		{object}	problem			"Post not found"
+//	@Failure		500		{object}	problem			"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/comments [get]
+func (app *application) getCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+
+	app.writeComments(w, r, post.ID, nil, store.CommentQuery{
+		ViewerID: getAuthUserFromCtx(r).ID,
+		Limit:    20,
+		Sort:     "desc",
+		Format:   "tree",
+		Depth:    1,
+		Replies:  3,
+	})
+}
+
+// GetReplies godoc
+//
+//	@Summary		Fetch Replies
+//	@Description	Fetch a page of the replies to a comment with their own nested replies
+//	@Tags			comments
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID		path		int				true	"Post ID"
+//	@Param			commentID	path		int				true	"Comment ID"
+//	@Param			limit		query		int				false	"Limit"
+//	@Param			sort		query		string			false	"Sort"
+//	@Param			cursor		query		string			false	"Cursor from next_cursor or prev_cursor"
+//	@Param			format		query		string			false	"tree (default) or flat"
+//	@Param			depth		query		int				false	"Levels of replies to include"
+//	@Param			replies		query		int				false	"Replies to include per comment"
+//	@Success		200			{object}	[]store.Comment	"Replies"
+//	@Failure		400			{object}	problem			"Invalid Comment Query"
+//	@Failure		404			{object}	problem			"Comment not found"
+//	@Failure		500			{object}	problem			"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/comments/{commentID}/replies [get]
+func (app *application) getRepliesHandler(w http.ResponseWriter, r *http.Request) {
+	parent := getCommentFromCtx(r)
+
+	app.writeComments(w, r, parent.PostID, parent, store.CommentQuery{
+		ParentID: &parent.ID,
+		ViewerID: getAuthUserFromCtx(r).ID,
+		Limit:    20,
+		Sort:     "asc",
+		Format:   "tree",
+		Depth:    1,
+		Replies:  3,
+	})
+}
+
+// writeComments responds with the page of comments described by the request
+// query, using cq for defaults. parent is the comment being replied to, nil
+// for top-level comments.
+func (app *application) writeComments(w http.ResponseWriter, r *http.Request, postID int64, parent *store.Comment, cq store.CommentQuery) {
+	cq, err := cq.Parse(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := validate.Struct(cq); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	cq, err = cq.DecodeCursor([]byte(app.config.pagination.cursorSecret))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	comments, page, err := app.store.Comments.GetByPostID(r.Context(), postID, cq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if cq.Format == "flat" {
+		path := []int64{}
+		if parent != nil {
+			path = append(path, parent.ID)
+		}
+		comments = store.FlattenComments(comments, path)
+	}
+
+	if err := app.paginatedJSONResponse(w, http.StatusOK, comments, page); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
 // DeleteComment godoc
 //
 //	@Summary		Delete Comment
-//	@Description	Delete Comment details by ID
+//	@Description	Move a comment to the trash, from where it can be restored until it is purged
 //	@Tags			comments
 //	@Accept			json
 //	@Produce		json
 //	@Param			postID		path		int		true	"Post ID"
 //	@Param			commentID	path		int		true	"Comment ID"
 //	@Success		204			{object}	nil		"Comment Deleted"
-//	@Failure		404			{object}	error	"Comment Not found"
-//	@Failure		500			{object}	error	"Something went wrong"
+//	@Failure		403			{object}	problem	"Forbidden"
+//	@Failure		404			{object}	problem	"Comment Not found"
+//	@Failure		500			{object}	problem	"Something went wrong"
 //	@Security		ApiKeyAuth
 //	@Router			/posts/{postID}/comments/{commentID} [delete]
 func (app *application) deleteCommentHandler(w http.ResponseWriter, r *http.Request) {
-	paramID := chi.URLParam(r, "commentID")
-	commentID, err := strconv.ParseInt(paramID, 10, 64)
-	if err != nil {
-		app.internalServerError(w, r, err)
-		return
-	}
+	comment := getCommentFromCtx(r)
 
 	ctx := r.Context()
 
-	err = app.store.Comments.Delete(ctx, commentID)
+	err := app.store.Comments.Delete(ctx, comment.ID)
 
 	if err != nil {
 		switch err {
@@ -101,3 +286,51 @@ func (app *application) deleteCommentHandler(w http.ResponseWriter, r *http.Requ
 	w.WriteHeader(http.StatusNoContent)
 
 }
+
+func (app *application) commentsContextMiddleware(next http.Handler) http.Handler {
+	return app.commentContext(app.store.Comments.GetByID)(next)
+}
+
+// commentContext loads the comment named in the URL with get and stores it
+// in the request context.
+func (app *application) commentContext(get func(context.Context, int64) (*store.Comment, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idParam := chi.URLParam(r, commentURLParam)
+			commentID, err := strconv.ParseInt(idParam, 10, 64)
+			if err != nil {
+				app.badRequestResponse(w, r, err)
+				return
+			}
+
+			ctx := r.Context()
+			comment, err := get(ctx, commentID)
+			if err != nil {
+				switch {
+				case errors.Is(err, store.ErrNotFound):
+					app.notFoundResponse(w, r, err)
+					return
+				default:
+					app.internalServerError(w, r, err)
+					return
+				}
+			}
+
+			// a comment is only addressable through the post it belongs to
+			post := getPostFromCtx(r)
+			if comment.PostID != post.ID {
+				app.notFoundResponse(w, r, store.ErrNotFound)
+				return
+			}
+
+			ctx = context.WithValue(ctx, commentCtx, comment)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func getCommentFromCtx(r *http.Request) *store.Comment {
+	comment, _ := r.Context().Value(commentCtx).(*store.Comment)
+	return comment
+}
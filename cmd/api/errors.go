@@ -1,25 +1,84 @@
 package main
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
 )
 
 func (app *application) internalServerError(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Errorf("internal server error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
-	writeJSONError(w, http.StatusInternalServerError, "the server encountered a problem")
+	app.requestLogger(r).Errorw("internal server error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	writeProblem(w, r, http.StatusInternalServerError, "the server encountered a problem", nil)
 }
 
 func (app *application) badRequestResponse(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Warnf("bad request error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
-	writeJSONError(w, http.StatusBadRequest, err.Error())
+	app.requestLogger(r).Warnw("bad request error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		trans := validationTranslator(r)
+		errs := make([]fieldError, len(verrs))
+		for i, fe := range verrs {
+			// drop the name of the payload struct from the namespace
+			_, field, _ := strings.Cut(fe.Namespace(), ".")
+			errs[i] = fieldError{Field: field, Message: fe.Translate(trans)}
+		}
+		writeProblem(w, r, http.StatusBadRequest, "the request is invalid", errs)
+		return
+	}
+
+	writeProblem(w, r, http.StatusBadRequest, err.Error(), nil)
+}
+
+func (app *application) conflictResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.requestLogger(r).Warnw("conflict error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	writeProblem(w, r, http.StatusConflict, "the resource has been modified by another request, try again", nil)
+}
+
+// alreadyExistsResponse reports a conflict with existing state, such as a
+// taken username, described by err.
+func (app *application) alreadyExistsResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.requestLogger(r).Warnw("already exists error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	writeProblem(w, r, http.StatusConflict, err.Error(), nil)
+}
+
+func (app *application) preconditionFailedResponse(w http.ResponseWriter, r *http.Request) {
+	app.requestLogger(r).Warnw("precondition failed", "method", r.Method, "path", r.URL.Path)
+	writeProblem(w, r, http.StatusPreconditionFailed, "the resource has been modified since it was fetched", nil)
 }
 
-// func (app *application) conflictResponse(w http.ResponseWriter,r *http.Request,err error){
-// 	log.Printf("conflict error: %s path: %s error: %s\n",r.Method,r.URL.Path,err)
-// 	writeJSONError(w,http.StatusConflict,err.Error())
-// }
+func (app *application) preconditionRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	app.requestLogger(r).Warnw("precondition required", "method", r.Method, "path", r.URL.Path)
+	writeProblem(w, r, http.StatusPreconditionRequired, "the If-Match header is required", nil)
+}
 
 func (app *application) notFoundResponse(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Errorf("not found error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
-	writeJSONError(w, http.StatusNotFound, "not found")
+	app.requestLogger(r).Warnw("not found error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	writeProblem(w, r, http.StatusNotFound, "not found", nil)
+}
+
+func (app *application) unauthorizedErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.requestLogger(r).Warnw("unauthorized error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	w.Header().Set("WWW-Authenticate", `Bearer realm="restricted"`)
+	writeProblem(w, r, http.StatusUnauthorized, "unauthorized", nil)
+}
+
+func (app *application) unauthorizedBasicErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.requestLogger(r).Warnw("unauthorized basic error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	w.Header().Set("WWW-Authenticate", `Basic realm="restricted", charset="UTF-8"`)
+	writeProblem(w, r, http.StatusUnauthorized, "unauthorized", nil)
+}
+
+func (app *application) forbiddenResponse(w http.ResponseWriter, r *http.Request) {
+	app.requestLogger(r).Warnw("forbidden", "method", r.Method, "path", r.URL.Path)
+	writeProblem(w, r, http.StatusForbidden, "forbidden", nil)
+}
+
+func (app *application) rateLimitExceededResponse(w http.ResponseWriter, r *http.Request, retryAfter int) {
+	app.requestLogger(r).Warnw("rate limit exceeded", "method", r.Method, "path", r.URL.Path)
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	writeProblem(w, r, http.StatusTooManyRequests, "rate limit exceeded, retry after: "+strconv.Itoa(retryAfter)+"s", nil)
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+func TestProblemResponses(t *testing.T) {
+	app, _ := newTestApplication(t)
+	mux := app.mount()
+
+	alice := newTestUser(t, app, "alice", "user")
+
+	decodeProblem := func(t *testing.T, req *http.Request, status int) problem {
+		t.Helper()
+
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, status, rr)
+
+		if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Fatalf("expected an application/problem+json response, got %q", ct)
+		}
+
+		var p problem
+		if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+			t.Fatal(err)
+		}
+		if p.Status != status || p.Title != http.StatusText(status) {
+			t.Fatalf("unexpected problem %+v", p)
+		}
+		return p
+	}
+
+	t.Run("should report invalid fields by their JSON name", func(t *testing.T) {
+		payload := map[string]any{"content": "hello"}
+		req := newTestRequest(t, app, http.MethodPost, "/v1/posts", alice, payload)
+
+		p := decodeProblem(t, req, http.StatusBadRequest)
+		if len(p.Errors) != 1 {
+			t.Fatalf("expected one field error, got %+v", p.Errors)
+		}
+		if want := (fieldError{Field: "title", Message: "title is a required field"}); p.Errors[0] != want {
+			t.Fatalf("expected %+v, got %+v", want, p.Errors[0])
+		}
+	})
+
+	t.Run("should reject malformed input as a bad request", func(t *testing.T) {
+		post := &store.Post{UserID: alice.ID, Title: "Hello", Content: "World"}
+		if err := app.store.Posts.Create(context.Background(), post); err != nil {
+			t.Fatal(err)
+		}
+		commentsURL := fmt.Sprintf("/v1/posts/%d/comments", post.ID)
+
+		requests := []*http.Request{
+			newTestRequest(t, app, http.MethodGet, "/v1/posts/abc", alice, nil),
+			newTestRequest(t, app, http.MethodGet, "/v1/users/abc", alice, nil),
+			newTestRequest(t, app, http.MethodPost, commentsURL, alice, map[string]any{"text": "hello"}),
+		}
+		for _, req := range requests {
+			decodeProblem(t, req, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("should set the request ID as instance", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodGet, "/v1/users/999", alice, nil)
+		req.Header.Set("X-Request-Id", "test-request")
+
+		p := decodeProblem(t, req, http.StatusNotFound)
+		if p.Instance != "test-request" {
+			t.Fatalf("expected the instance to be the request ID, got %q", p.Instance)
+		}
+	})
+}
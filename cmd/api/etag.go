@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+// versionETag returns the weak entity tag of a post at version, which
+// covers the fields that are only changed through a new version.
+func versionETag(version int) string {
+	return fmt.Sprintf(`W/"%d"`, version)
+}
+
+// postETag returns the weak entity tag of post as fetched by viewerID, with
+// its embedded comments and reactions. Those change without bumping the
+// version, and reacted_by_me differs between viewers, so the version tag is
+// extended with a digest of both and of the viewer.
+func postETag(post *store.Post, viewerID int64) (string, error) {
+	embedded, err := json.Marshal(struct {
+		ViewerID  int64
+		Comments  []store.Comment
+		Reactions []store.ReactionCount
+	}{viewerID, post.Comments, post.Reactions})
+	if err != nil {
+		return "", err
+	}
+
+	h := fnv.New64a()
+	h.Write(embedded)
+
+	return fmt.Sprintf(`W/"%d-%x"`, post.Version, h.Sum64()), nil
+}
+
+// etagMatches reports whether header, the value of an If-None-Match header,
+// is "*" or lists etag. Tags are compared weakly, ignoring their W/ prefix.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// versionMatches reports whether header, the value of an If-Match header,
+// is "*" or lists a tag of the post at version. Every tag we hand out is
+// weak, so tags are compared weakly, ignoring their W/ prefix. Both the
+// version tag and the tag of a fetched post match: comments and reactions
+// aren't edited through the post, so a precondition only pins the version a
+// client has seen.
+func versionMatches(header string, version int) bool {
+	v := strconv.Itoa(version)
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+
+		opaque, ok := strings.CutPrefix(strings.TrimPrefix(tag, "W/"), `"`)
+		if !ok || !strings.HasSuffix(opaque, `"`) {
+			continue
+		}
+		opaque = strings.TrimSuffix(opaque, `"`)
+		if base, _, _ := strings.Cut(opaque, "-"); base == v {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch checks the If-Match header of r against version, the current
+// version of the post, and writes the error response when the request must
+// not go on. A missing header is only rejected when required is set.
+func (app *application) checkIfMatch(w http.ResponseWriter, r *http.Request, version int, required bool) bool {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		if required {
+			app.preconditionRequiredResponse(w, r)
+			return false
+		}
+		return true
+	}
+
+	if !versionMatches(ifMatch, version) {
+		app.preconditionFailedResponse(w, r)
+		return false
+	}
+	return true
+}
@@ -15,12 +15,13 @@ import (
 //	@Produce		json
 //	@Param			limit	query		int							false	"Limit"
 //	@Param			offset	query		int							false	"Offset"
+//	@Param			cursor	query		string						false	"Cursor from next_cursor or prev_cursor"
 //	@Param			sort	query		string						false	"Sort"
 //	@Param			tags	query		string						false	"Tags"
 //	@Success		200		{object}	[]store.PostWithMetadata	"User Feed"
-//	@Failure		400		{object}	error						"Invalid Feed payload"
-//	@Failure		404		{object}	error						"Feed not found"
-//	@Failure		500		{object}	error						"Something went wrong"
+//	@Failure		400		{object}	problem						"Invalid Feed payload"
+//	@Failure		404		{object}	problem						"Feed not found"
+//	@Failure		500		{object}	problem						"Something went wrong"
 //	@Security		ApiKeyAuth
 //	@Router			/users/feed [get]
 func (app *application) getUserFeedHandler(w http.ResponseWriter, r *http.Request) {
@@ -41,15 +42,22 @@ func (app *application) getUserFeedHandler(w http.ResponseWriter, r *http.Reques
 		app.badRequestResponse(w, r, err)
 		return
 	}
+	fq, err = fq.DecodeCursor([]byte(app.config.pagination.cursorSecret))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
 	ctx := r.Context()
 
-	feed, err := app.store.Posts.GetUserFeed(ctx, int64(104), fq)
+	user := getAuthUserFromCtx(r)
+
+	feed, page, err := app.store.Posts.GetUserFeed(ctx, user.ID, fq)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, feed); err != nil {
+	if err := app.paginatedJSONResponse(w, http.StatusOK, feed, page); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+func TestGetUserFeed(t *testing.T) {
+	app, _ := newTestApplication(t)
+	mux := app.mount()
+
+	alice := newTestUser(t, app, "alice", "user")
+	bob := newTestUser(t, app, "bob", "user")
+	carol := newTestUser(t, app, "carol", "user")
+
+	ctx := context.Background()
+	if err := app.store.Users.Follow(ctx, alice.ID, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []*store.Post{
+		{UserID: alice.ID, Title: "mine", Content: "go", Tags: []string{"go"}},
+		{UserID: bob.ID, Title: "followed", Content: "go", Tags: []string{"go"}},
+		{UserID: bob.ID, Title: "followed", Content: "rust", Tags: []string{"rust"}},
+		{UserID: carol.ID, Title: "stranger", Content: "go", Tags: []string{"go"}},
+	} {
+		if err := app.store.Posts.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	getFeed := func(t *testing.T, query url.Values) ([]store.PostWithMetadata, string) {
+		t.Helper()
+
+		req := newTestRequest(t, app, http.MethodGet, "/v1/users/feed?"+query.Encode(), alice, nil)
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr)
+
+		var page struct {
+			Data       []store.PostWithMetadata `json:"data"`
+			NextCursor string                   `json:"next_cursor"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+			t.Fatal(err)
+		}
+		return page.Data, page.NextCursor
+	}
+
+	t.Run("should only include own and followed posts", func(t *testing.T) {
+		feed, _ := getFeed(t, url.Values{})
+		if len(feed) != 3 {
+			t.Fatalf("expected 3 posts, got %d", len(feed))
+		}
+		for _, p := range feed {
+			if p.UserID == carol.ID {
+				t.Fatalf("unexpected post %d from a user alice doesn't follow", p.ID)
+			}
+		}
+	})
+
+	t.Run("should filter by tags", func(t *testing.T) {
+		feed, _ := getFeed(t, url.Values{"tags": {"rust"}})
+		if len(feed) != 1 || feed[0].Tags[0] != "rust" {
+			t.Fatalf("expected the rust post only, got %+v", feed)
+		}
+	})
+
+	t.Run("should page with cursors", func(t *testing.T) {
+		seen := map[int64]bool{}
+		query := url.Values{"limit": {"2"}}
+		for {
+			feed, next := getFeed(t, query)
+			for _, p := range feed {
+				if seen[p.ID] {
+					t.Fatalf("post %d returned twice", p.ID)
+				}
+				seen[p.ID] = true
+			}
+			if next == "" {
+				break
+			}
+			query.Set("cursor", next)
+		}
+		if len(seen) != 3 {
+			t.Fatalf("expected 3 posts across pages, got %d", len(seen))
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sharukh010/social/internal/store"
+)
+
+type UpdatePrivacyPayload struct {
+	IsPrivate *bool `json:"is_private" validate:"required"`
+}
+
+// requestFollow asks the private account target to let user follow it,
+// unless user already does.
+func (app *application) requestFollow(w http.ResponseWriter, r *http.Request, user, target *store.User) {
+	ctx := r.Context()
+
+	rel, err := app.store.Followers.GetRelationship(ctx, target.ID, user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if rel.IsFollowing {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	fr := &store.FollowRequest{
+		RequesterID: user.ID,
+		TargetID:    target.ID,
+		Requester:   *user,
+	}
+	if err := app.store.FollowRequests.Create(ctx, fr); err != nil {
+		switch err {
+		case store.ErrBlocked:
+			app.forbiddenResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusAccepted, fr); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// UpdatePrivacy godoc
+//
+//	@Summary		Update account privacy
+//	@Description	Make the authenticated user's account private, so that follows need approval, or public, which approves the pending follow requests
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		UpdatePrivacyPayload	true	"Privacy"
+//	@Success		204		{object}	nil						"Privacy Updated"
+//	@Failure		400		{object}	problem					"Invalid Privacy Payload"
+//	@Failure		401		{object}	problem					"Unauthorized"
+//	@Failure		500		{object}	problem					"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/privacy [put]
+func (app *application) updatePrivacyHandler(w http.ResponseWriter, r *http.Request) {
+	var payload UpdatePrivacyPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getAuthUserFromCtx(r)
+
+	if err := app.store.Users.SetPrivate(r.Context(), user.ID, *payload.IsPrivate); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetFollowRequests godoc
+//
+//	@Summary		List follow requests
+//	@Description	Fetch a page of the pending requests to follow the authenticated user, most recent first
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			limit	query		int						false	"Limit"
+//	@Param			cursor	query		string					false	"Cursor from next_cursor or prev_cursor"
+//	@Success		200		{object}	[]store.FollowRequest	"Pending Follow Requests"
+//	@Failure		400		{object}	problem					"Invalid follow query"
+//	@Failure		401		{object}	problem					"Unauthorized"
+//	@Failure		500		{object}	problem					"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/follow-requests [get]
+func (app *application) getFollowRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	fq := store.FollowQuery{
+		Limit: 20,
+	}
+
+	fq, err := fq.Parse(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := validate.Struct(fq); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	fq, err = fq.DecodeCursor([]byte(app.config.pagination.cursorSecret))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getAuthUserFromCtx(r)
+
+	requests, page, err := app.store.FollowRequests.GetPending(r.Context(), user.ID, fq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.paginatedJSONResponse(w, http.StatusOK, requests, page); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// ApproveFollowRequest godoc
+//
+//	@Summary		Approve a follow request
+//	@Description	Approve a user's pending request to follow the authenticated user
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path		int		true	"Requester ID"
+//	@Success		204		{object}	nil		"Follow Request Approved"
+//	@Failure		401		{object}	problem	"Unauthorized"
+//	@Failure		404		{object}	problem	"Follow request not found"
+//	@Failure		500		{object}	problem	"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/follow-requests/{userID}/approve [put]
+func (app *application) approveFollowRequestHandler(w http.ResponseWriter, r *http.Request) {
+	app.answerFollowRequest(w, r, app.store.FollowRequests.Approve)
+}
+
+// RejectFollowRequest godoc
+//
+//	@Summary		Reject a follow request
+//	@Description	Reject a user's pending request to follow the authenticated user
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path		int		true	"Requester ID"
+//	@Success		204		{object}	nil		"Follow Request Rejected"
+//	@Failure		401		{object}	problem	"Unauthorized"
+//	@Failure		404		{object}	problem	"Follow request not found"
+//	@Failure		500		{object}	problem	"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/follow-requests/{userID}/reject [put]
+func (app *application) rejectFollowRequestHandler(w http.ResponseWriter, r *http.Request) {
+	app.answerFollowRequest(w, r, app.store.FollowRequests.Reject)
+}
+
+// answerFollowRequest applies answer to the pending request of the user in
+// the URL to follow the authenticated user.
+func (app *application) answerFollowRequest(w http.ResponseWriter, r *http.Request, answer func(context.Context, int64, int64) error) {
+	requesterID, err := strconv.ParseInt(chi.URLParam(r, userURLParam), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getAuthUserFromCtx(r)
+
+	if err := answer(r.Context(), user.ID, requesterID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
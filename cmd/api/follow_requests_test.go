@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+func TestFollowRequests(t *testing.T) {
+	app, _ := newTestApplication(t)
+	mux := app.mount()
+
+	alice := newTestUser(t, app, "alice", "user")
+	bob := newTestUser(t, app, "bob", "user")
+	carol := newTestUser(t, app, "carol", "user")
+
+	setPrivate := func(t *testing.T, user *store.User, private bool) {
+		t.Helper()
+
+		req := newTestRequest(t, app, http.MethodPut, "/v1/users/me/privacy", user, UpdatePrivacyPayload{IsPrivate: &private})
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+	}
+	follow := func(t *testing.T, follower, followed *store.User, code int) {
+		t.Helper()
+
+		url := fmt.Sprintf("/v1/users/%d/follow", followed.ID)
+		req := newTestRequest(t, app, http.MethodPut, url, follower, nil)
+		checkResponseCode(t, code, executeRequest(req, mux))
+	}
+	answer := func(t *testing.T, target, requester *store.User, action string, code int) {
+		t.Helper()
+
+		url := fmt.Sprintf("/v1/users/me/follow-requests/%d/%s", requester.ID, action)
+		req := newTestRequest(t, app, http.MethodPut, url, target, nil)
+		checkResponseCode(t, code, executeRequest(req, mux))
+	}
+	pending := func(t *testing.T, user *store.User) []store.FollowRequest {
+		t.Helper()
+
+		req := newTestRequest(t, app, http.MethodGet, "/v1/users/me/follow-requests", user, nil)
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr)
+
+		var requests []store.FollowRequest
+		decodeData(t, rr, &requests)
+		return requests
+	}
+
+	setPrivate(t, alice, true)
+
+	req := newTestRequest(t, app, http.MethodPost, "/v1/posts/", alice, CreatePostPayload{Title: "Hello", Content: "World"})
+	rr := executeRequest(req, mux)
+	checkResponseCode(t, http.StatusCreated, rr)
+
+	var post store.Post
+	decodeData(t, rr, &post)
+	postURL := fmt.Sprintf("/v1/posts/%d", post.ID)
+
+	getPost := func(t *testing.T, user *store.User, code int) {
+		t.Helper()
+
+		req := newTestRequest(t, app, http.MethodGet, postURL, user, nil)
+		checkResponseCode(t, code, executeRequest(req, mux))
+	}
+
+	t.Run("should hide the posts of private accounts from non-followers", func(t *testing.T) {
+		getPost(t, alice, http.StatusOK)
+		getPost(t, bob, http.StatusNotFound)
+	})
+
+	t.Run("should request to follow private accounts", func(t *testing.T) {
+		follow(t, bob, alice, http.StatusAccepted)
+		follow(t, carol, alice, http.StatusAccepted)
+
+		requests := pending(t, alice)
+		if len(requests) != 2 {
+			t.Fatalf("expected 2 pending requests, got %d", len(requests))
+		}
+		if requests[0].RequesterID != carol.ID || requests[0].Requester.Username != carol.Username {
+			t.Fatalf("expected carol's request first, got %+v", requests[0])
+		}
+
+		getPost(t, bob, http.StatusNotFound)
+	})
+
+	t.Run("should make approved requesters followers", func(t *testing.T) {
+		answer(t, alice, bob, "approve", http.StatusNoContent)
+		answer(t, alice, bob, "approve", http.StatusNotFound)
+
+		getPost(t, bob, http.StatusOK)
+
+		req := newTestRequest(t, app, http.MethodGet, "/v1/users/feed", bob, nil)
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr)
+
+		var feed []store.PostWithMetadata
+		decodeData(t, rr, &feed)
+		if len(feed) != 1 || feed[0].ID != post.ID {
+			t.Fatalf("expected alice's post in bob's feed, got %+v", feed)
+		}
+
+		follow(t, bob, alice, http.StatusNoContent)
+	})
+
+	t.Run("should reject requests", func(t *testing.T) {
+		answer(t, alice, carol, "reject", http.StatusNoContent)
+		answer(t, alice, carol, "approve", http.StatusNotFound)
+
+		if requests := pending(t, alice); len(requests) != 0 {
+			t.Fatalf("expected no pending requests, got %+v", requests)
+		}
+		getPost(t, carol, http.StatusNotFound)
+	})
+
+	t.Run("should approve pending requests when going public", func(t *testing.T) {
+		follow(t, carol, alice, http.StatusAccepted)
+		setPrivate(t, alice, false)
+
+		rel, err := app.store.Followers.GetRelationship(req.Context(), alice.ID, carol.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !rel.IsFollowing {
+			t.Fatal("expected carol to follow alice")
+		}
+		if requests := pending(t, alice); len(requests) != 0 {
+			t.Fatalf("expected no pending requests, got %+v", requests)
+		}
+	})
+}
+
+func TestBlockRejectsFollowRequests(t *testing.T) {
+	app, _ := newTestApplication(t)
+	mux := app.mount()
+
+	alice := newTestUser(t, app, "alice", "user")
+	bob := newTestUser(t, app, "bob", "user")
+
+	setPrivate := func(t *testing.T, private bool) {
+		t.Helper()
+
+		req := newTestRequest(t, app, http.MethodPut, "/v1/users/me/privacy", alice, UpdatePrivacyPayload{IsPrivate: &private})
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+	}
+
+	setPrivate(t, true)
+
+	req := newTestRequest(t, app, http.MethodPut, fmt.Sprintf("/v1/users/%d/follow", alice.ID), bob, nil)
+	checkResponseCode(t, http.StatusAccepted, executeRequest(req, mux))
+
+	req = newTestRequest(t, app, http.MethodPut, fmt.Sprintf("/v1/users/%d/block", bob.ID), alice, nil)
+	checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+
+	req = newTestRequest(t, app, http.MethodPut, fmt.Sprintf("/v1/users/me/follow-requests/%d/approve", bob.ID), alice, nil)
+	checkResponseCode(t, http.StatusNotFound, executeRequest(req, mux))
+
+	setPrivate(t, false)
+
+	rel, err := app.store.Followers.GetRelationship(req.Context(), alice.ID, bob.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rel.IsFollowing {
+		t.Fatal("expected the block to keep bob from following alice")
+	}
+}
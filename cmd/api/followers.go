@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+// GetUserFollowers godoc
+//
+//	@Summary		Fetch Followers
+//	@Description	Fetch a page of the users following a user, most recent first. The lists of private accounts the caller doesn't follow, and of users blocking or blocked by the caller, are reported as not found.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path		int				true	"User ID"
+//	@Param			limit	query		int				false	"Limit"
+//	@Param			cursor	query		string			false	"Cursor from next_cursor or prev_cursor"
+//	@Success		200		{object}	[]store.Follow	"Followers"
+//	@Failure		400		{object}	problem			"Invalid follow query"
+//	@Failure		404		{object}	problem			"User not found"
+//	@Failure		500		{object}	problem			"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/followers [get]
+func (app *application) getUserFollowersHandler(w http.ResponseWriter, r *http.Request) {
+	app.writeFollows(w, r, app.store.Followers.GetFollowers)
+}
+
+// GetUserFollowing godoc
+//
+//	@Summary		Fetch Following
+//	@Description	Fetch a page of the users a user follows, most recent first. The lists of private accounts the caller doesn't follow, and of users blocking or blocked by the caller, are reported as not found.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path		int				true	"User ID"
+//	@Param			limit	query		int				false	"Limit"
+//	@Param			cursor	query		string			false	"Cursor from next_cursor or prev_cursor"
+//	@Success		200		{object}	[]store.Follow	"Followed users"
+//	@Failure		400		{object}	problem			"Invalid follow query"
+//	@Failure		404		{object}	problem			"User not found"
+//	@Failure		500		{object}	problem			"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/following [get]
+func (app *application) getUserFollowingHandler(w http.ResponseWriter, r *http.Request) {
+	app.writeFollows(w, r, app.store.Followers.GetFollowing)
+}
+
+// GetUserMutuals godoc
+//
+//	@Summary		Fetch Mutual Follows
+//	@Description	Fetch a page of the users a user follows who follow them back, most recent first. The lists of private accounts the caller doesn't follow, and of users blocking or blocked by the caller, are reported as not found.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path		int				true	"User ID"
+//	@Param			limit	query		int				false	"Limit"
+//	@Param			cursor	query		string			false	"Cursor from next_cursor or prev_cursor"
+//	@Success		200		{object}	[]store.Follow	"Mutual follows"
+//	@Failure		400		{object}	problem			"Invalid follow query"
+//	@Failure		404		{object}	problem			"User not found"
+//	@Failure		500		{object}	problem			"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/mutuals [get]
+func (app *application) getUserMutualsHandler(w http.ResponseWriter, r *http.Request) {
+	app.writeFollows(w, r, app.store.Followers.GetMutuals)
+}
+
+// writeFollows responds with the page of the user's follows that list
+// returns for the request query, or with 404 when the user's posts are
+// hidden from the viewer by a block or a private account.
+func (app *application) writeFollows(w http.ResponseWriter, r *http.Request, list func(context.Context, int64, store.FollowQuery) ([]store.Follow, store.Page, error)) {
+	user := getUserFromCtx(r)
+	viewer := getAuthUserFromCtx(r)
+
+	// the follows of an account are shown to those who may see its posts
+	visible, err := app.canSeePostsOf(r.Context(), viewer, user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !visible {
+		app.notFoundResponse(w, r, store.ErrNotFound)
+		return
+	}
+
+	fq := store.FollowQuery{
+		ViewerID: viewer.ID,
+		Limit:    20,
+	}
+
+	fq, err = fq.Parse(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := validate.Struct(fq); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	fq, err = fq.DecodeCursor([]byte(app.config.pagination.cursorSecret))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	follows, page, err := list(r.Context(), user.ID, fq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.paginatedJSONResponse(w, http.StatusOK, follows, page); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
@@ -17,7 +17,8 @@ type healthResponse struct {
 //	@Tags			Health
 //	@Produce		json
 //	@Success		200	{object}	healthResponse	"API is live"
-//	@Failure		500	{object}	error			"Something went worng"
+//	@Failure		503	{object}	healthResponse	"API is shutting down"
+//	@Failure		500	{object}	problem			"Something went worng"
 //	@Security		ApiKeyAuth
 //	@Router			/health [get]
 func (app *application) healthCheckHandler(w http.ResponseWriter, r *http.Request) {
@@ -26,7 +27,12 @@ func (app *application) healthCheckHandler(w http.ResponseWriter, r *http.Reques
 		Env:     app.config.env,
 		Version: version,
 	}
-	if err := app.jsonResponse(w, http.StatusOK, data); err != nil {
+	status := http.StatusOK
+	if app.draining.Load() {
+		data.Status = "draining"
+		status = http.StatusServiceUnavailable
+	}
+	if err := app.jsonResponse(w, status, data); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHealthDuringShutdown(t *testing.T) {
+	app, _ := newTestApplication(t)
+	app.config.shutdownDelay = time.Millisecond * 500
+	app.config.shutdownTimeout = time.Second
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: app.mount()}
+	go srv.Serve(ln)
+
+	url := "http://" + ln.Addr().String() + "/v1/health"
+	health := func(t *testing.T) (int, string) {
+		t.Helper()
+
+		res, err := http.Get(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		var envelope struct {
+			Data healthResponse `json:"data"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&envelope); err != nil {
+			t.Fatal(err)
+		}
+		return res.StatusCode, envelope.Data.Status
+	}
+
+	if code, status := health(t); code != http.StatusOK || status != "ok" {
+		t.Fatalf("expected 200 ok before shutdown, got %d %s", code, status)
+	}
+
+	done := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		done <- app.shutdown(srv, func() {})
+	}()
+
+	t.Run("should report draining while the delay runs", func(t *testing.T) {
+		for !app.draining.Load() {
+			time.Sleep(time.Millisecond)
+		}
+
+		code, status := health(t)
+		if code != http.StatusServiceUnavailable || status != "draining" {
+			t.Fatalf("expected 503 draining, got %d %s", code, status)
+		}
+		select {
+		case err := <-done:
+			t.Fatalf("expected the server to keep serving during the delay, shut down with %v", err)
+		default:
+		}
+	})
+
+	t.Run("should stop the server after the delay", func(t *testing.T) {
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+		if elapsed := time.Since(start); elapsed < app.config.shutdownDelay {
+			t.Fatalf("expected shutdown to wait %s, took %s", app.config.shutdownDelay, elapsed)
+		}
+		if _, err := http.Get(url); err == nil {
+			t.Fatal("expected the server to refuse connections")
+		}
+	})
+}
@@ -3,14 +3,96 @@ package main
 import (
 	"encoding/json"
 	"net/http"
+	"reflect"
+	"strings"
 
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	"github.com/sharukh010/social/internal/store"
 )
 
-var validate *validator.Validate
+var (
+	validate    *validator.Validate
+	translators *ut.UniversalTranslator
+)
+
+// validationTranslations lists the locales validation messages are
+// translated to. The first one is used when the Accept-Language header
+// matches none of them.
+var validationTranslations = []struct {
+	locale   locales.Translator
+	register func(*validator.Validate, ut.Translator) error
+}{
+	{en.New(), en_translations.RegisterDefaultTranslations},
+}
 
 func init() {
 	validate = validator.New(validator.WithRequiredStructEnabled())
+
+	// name fields after their JSON key, which is also the query parameter
+	validate.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	// usernames must be mentionable
+	err := validate.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+		return store.ValidUsername(fl.Field().String())
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	supported := make([]locales.Translator, len(validationTranslations))
+	for i, t := range validationTranslations {
+		supported[i] = t.locale
+	}
+	translators = ut.New(supported[0], supported...)
+
+	for _, t := range validationTranslations {
+		trans, _ := translators.GetTranslator(t.locale.Locale())
+		if err := t.register(validate, trans); err != nil {
+			panic(err)
+		}
+	}
+
+	trans, _ := translators.GetTranslator("en")
+	err = validate.RegisterTranslation("username", trans, func(ut ut.Translator) error {
+		return ut.Add("username", "{0} may only contain letters, digits, '_', '.' and '-', and must end with a letter, digit or '_'", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("username", fe.Field())
+		return t
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// validationTranslator picks the translator for the languages in the
+// Accept-Language header of r, in the order they are listed.
+func validationTranslator(r *http.Request) ut.Translator {
+	var tags []string
+	for _, tag := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, _, _ = strings.Cut(strings.TrimSpace(tag), ";")
+		if tag == "" {
+			continue
+		}
+		tag = strings.ReplaceAll(tag, "-", "_")
+		tags = append(tags, tag)
+		if lang, _, ok := strings.Cut(tag, "_"); ok {
+			tags = append(tags, lang)
+		}
+	}
+
+	trans, _ := translators.FindTranslator(tags...)
+	return trans
 }
 func writeJSON(w http.ResponseWriter, status int, data any) error {
 	w.Header().Set("Content-Type", "application/json")
@@ -28,11 +110,37 @@ func readJSON(w http.ResponseWriter, r *http.Request, data any) error {
 	return decoder.Decode(data)
 }
 
-func writeJSONError(w http.ResponseWriter, status int, message string) error {
-	type envelope struct {
-		Error string `json:"error"`
-	}
-	return writeJSON(w, status, &envelope{Error: message})
+// problem is an RFC 7807 problem details object.
+type problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Instance is the ID of the request the problem occurred in
+	Instance string       `json:"instance,omitempty"`
+	Errors   []fieldError `json:"errors,omitempty"`
+}
+
+// fieldError explains why a field of the request body or query failed
+// validation.
+type fieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// writeProblem writes an application/problem+json response with the
+// generic "about:blank" type, titled after status.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string, errs []fieldError) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(&problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: middleware.GetReqID(r.Context()),
+		Errors:   errs,
+	})
 }
 
 func (app *application) jsonResponse(w http.ResponseWriter, status int, data any) error {
@@ -42,3 +150,33 @@ func (app *application) jsonResponse(w http.ResponseWriter, status int, data any
 
 	return writeJSON(w, status, &envelop{Data: data})
 }
+
+// paginatedJSONResponse is jsonResponse with the signed cursors of the
+// previous and next pages alongside the data.
+func (app *application) paginatedJSONResponse(w http.ResponseWriter, status int, data any, page store.Page) error {
+	type envelop struct {
+		Data       any    `json:"data"`
+		NextCursor string `json:"next_cursor,omitempty"`
+		PrevCursor string `json:"prev_cursor,omitempty"`
+	}
+
+	secret := []byte(app.config.pagination.cursorSecret)
+	res := &envelop{Data: data}
+
+	if page.Next != nil {
+		next, err := page.Next.Encode(secret)
+		if err != nil {
+			return err
+		}
+		res.NextCursor = next
+	}
+	if page.Prev != nil {
+		prev, err := page.Prev.Encode(secret)
+		if err != nil {
+			return err
+		}
+		res.PrevCursor = prev
+	}
+
+	return writeJSON(w, status, res)
+}
@@ -0,0 +1,24 @@
+package main
+
+import "go.uber.org/zap"
+
+// newLogger builds a human readable logger in development and a JSON one
+// everywhere else, logging at level and above.
+func newLogger(env, level string) (*zap.SugaredLogger, error) {
+	cfg := zap.NewProductionConfig()
+	if env == "development" {
+		cfg = zap.NewDevelopmentConfig()
+	}
+
+	lvl, err := zap.ParseAtomicLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Level = lvl
+
+	logger, err := cfg.Build()
+	if err != nil {
+		return nil, err
+	}
+	return logger.Sugar(), nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLoggerMiddleware(t *testing.T) {
+	app, _ := newTestApplication(t)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	app.logger = zap.New(core).Sugar()
+	mux := app.mount()
+
+	alice := newTestUser(t, app, "alice", "user")
+
+	req := newTestRequest(t, app, http.MethodGet, fmt.Sprintf("/v1/users/%d", alice.ID), alice, nil)
+	checkResponseCode(t, http.StatusOK, executeRequest(req, mux))
+
+	entries := logs.FilterMessage("request completed").AllUntimed()
+	if len(entries) != 1 {
+		t.Fatalf("expected one access log entry, got %d", len(entries))
+	}
+
+	fields := entries[0].ContextMap()
+	for key, want := range map[string]any{
+		"method":  http.MethodGet,
+		"route":   "/v1/users/{userID}",
+		"status":  int64(http.StatusOK),
+		"user_id": alice.ID,
+	} {
+		if fields[key] != want {
+			t.Errorf("expected %s to be %v, got %v", key, want, fields[key])
+		}
+	}
+	for _, key := range []string{"request_id", "bytes", "duration", "remote_ip"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("expected the entry to have a %s field", key)
+		}
+	}
+}
+
+func TestNotFoundLogLevel(t *testing.T) {
+	app, _ := newTestApplication(t)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	app.logger = zap.New(core).Sugar()
+	mux := app.mount()
+
+	alice := newTestUser(t, app, "alice", "user")
+
+	req := newTestRequest(t, app, http.MethodGet, "/v1/users/999", alice, nil)
+	checkResponseCode(t, http.StatusNotFound, executeRequest(req, mux))
+
+	entries := logs.FilterMessage("not found error").AllUntimed()
+	if len(entries) != 1 || entries[0].Level != zapcore.WarnLevel {
+		t.Fatalf("expected one not found entry at warn level, got %+v", entries)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sharukh010/social/internal/store"
+)
+
+const metricsNamespace = "social"
+
+type metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+	queryErrors     *prometheus.CounterVec
+}
+
+// newMetrics registers the API metrics along with the Go runtime and, when
+// db is not nil, the connection pool stats of db.
+func newMetrics(db *sql.DB) *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by method, route pattern and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route pattern and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "store_query_duration_seconds",
+			Help:      "Store method latency.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		queryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "store_query_errors_total",
+			Help:      "Store method errors, split into not_found and other.",
+		}, []string{"method", "kind"}),
+	}
+
+	m.registry.MustRegister(
+		m.requests,
+		m.requestDuration,
+		m.queryDuration,
+		m.queryErrors,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	if db != nil {
+		m.registry.MustRegister(collectors.NewDBStatsCollector(db, "social"))
+	}
+
+	return m
+}
+
+// handler serves the registered metrics in the Prometheus text format.
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// storeHook records the duration and errors of every store method.
+func (m *metrics) storeHook(ctx context.Context, method string) (context.Context, func(error)) {
+	start := time.Now()
+
+	return ctx, func(err error) {
+		m.queryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+
+		switch {
+		case err == nil:
+		case errors.Is(err, store.ErrNotFound):
+			m.queryErrors.WithLabelValues(method, "not_found").Inc()
+		default:
+			m.queryErrors.WithLabelValues(method, "other").Inc()
+		}
+	}
+}
+
+// MetricsMiddleware counts and times every request, labelled by the route
+// pattern chi matched rather than the raw path to keep cardinality bounded.
+func (app *application) MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		labels := []string{r.Method, routePattern(r), strconv.Itoa(responseStatus(ww))}
+		app.metrics.requests.WithLabelValues(labels...).Inc()
+		app.metrics.requestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	})
+}
+
+// routePattern returns the pattern chi matched for r, such as
+// "/v1/posts/{postID}", or "unmatched" when no route matched.
+func routePattern(r *http.Request) string {
+	if pattern := chi.RouteContext(r.Context()).RoutePattern(); pattern != "" {
+		return pattern
+	}
+	return "unmatched"
+}
+
+// responseStatus returns the status written to ww, which is 200 when the
+// handler wrote a body without calling WriteHeader or wrote nothing at all.
+func responseStatus(ww middleware.WrapResponseWriter) int {
+	if status := ww.Status(); status != 0 {
+		return status
+	}
+	return http.StatusOK
+}
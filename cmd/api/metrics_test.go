@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	app, _ := newTestApplication(t)
+	mux := app.mount()
+
+	alice := newTestUser(t, app, "alice", "user")
+
+	req := newTestRequest(t, app, http.MethodGet, "/v1/users/999", alice, nil)
+	checkResponseCode(t, http.StatusNotFound, executeRequest(req, mux))
+
+	t.Run("should require the basic auth credentials", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodGet, "/v1/debug/metrics", nil, nil)
+		checkResponseCode(t, http.StatusUnauthorized, executeRequest(req, mux))
+
+		req = newTestRequest(t, app, http.MethodGet, "/v1/debug/metrics", nil, nil)
+		req.SetBasicAuth(app.config.auth.basic.user, "wrong")
+		checkResponseCode(t, http.StatusUnauthorized, executeRequest(req, mux))
+	})
+
+	req = newTestRequest(t, app, http.MethodGet, "/v1/debug/metrics", nil, nil)
+	req.SetBasicAuth(app.config.auth.basic.user, app.config.auth.basic.pass)
+	rr := executeRequest(req, mux)
+	checkResponseCode(t, http.StatusOK, rr)
+
+	body := rr.Body.String()
+	for _, want := range []string{
+		`social_http_requests_total{method="GET",route="/v1/users/{userID}",status="404"} 1`,
+		`social_store_query_errors_total{kind="not_found",method="Users.GetByID"} 1`,
+		`social_store_query_duration_seconds_count{method="Users.CreateAndInvite"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected the metrics to contain %s", want)
+		}
+	}
+}
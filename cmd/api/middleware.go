@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sharukh010/social/internal/ratelimiter"
+	"github.com/sharukh010/social/internal/store"
+)
+
+type accessLogKey struct{}
+
+var accessLogCtx accessLogKey
+
+// accessLogEntry collects the details of a request that are only known to
+// the middleware further down the chain, such as the authenticated user.
+type accessLogEntry struct {
+	userID int64
+}
+
+// LoggerMiddleware logs one line per request once it has been served.
+func (app *application) LoggerMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		entry := &accessLogEntry{}
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), accessLogCtx, entry)))
+
+		status := responseStatus(ww)
+		fields := []any{
+			"method", r.Method,
+			"route", routePattern(r),
+			"path", r.URL.Path,
+			"status", status,
+			"bytes", ww.BytesWritten(),
+			"duration", time.Since(start),
+			"remote_ip", clientIP(r),
+		}
+		if entry.userID != 0 {
+			fields = append(fields, "user_id", entry.userID)
+		}
+
+		logger := app.requestLogger(r)
+		if status >= http.StatusInternalServerError {
+			logger.Errorw("request completed", fields...)
+			return
+		}
+		logger.Infow("request completed", fields...)
+	})
+}
+
+// BasicAuthMiddleware guards operator endpoints with the configured basic
+// auth credentials, rejecting every request while no password is set.
+func (app *application) BasicAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok {
+			app.unauthorizedBasicErrorResponse(w, r, fmt.Errorf("authorization header is missing or malformed"))
+			return
+		}
+
+		basic := app.config.auth.basic
+		userMatch := subtle.ConstantTimeCompare([]byte(user), []byte(basic.user)) == 1
+		passMatch := subtle.ConstantTimeCompare([]byte(pass), []byte(basic.pass)) == 1
+		if basic.pass == "" || !userMatch || !passMatch {
+			app.unauthorizedBasicErrorResponse(w, r, fmt.Errorf("invalid credentials"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (app *application) AuthTokenMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" {
+			app.unauthorizedErrorResponse(w, r, fmt.Errorf("authorization header is missing"))
+			return
+		}
+
+		parts := strings.Split(authHeader, " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			app.unauthorizedErrorResponse(w, r, fmt.Errorf("authorization header is malformed"))
+			return
+		}
+
+		jwtToken, err := app.authenticator.ValidateToken(parts[1])
+		if err != nil {
+			app.unauthorizedErrorResponse(w, r, err)
+			return
+		}
+
+		claims, _ := jwtToken.Claims.(jwt.MapClaims)
+		sub, err := claims.GetSubject()
+		if err != nil {
+			app.unauthorizedErrorResponse(w, r, err)
+			return
+		}
+		userID, err := strconv.ParseInt(sub, 10, 64)
+		if err != nil {
+			app.unauthorizedErrorResponse(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+		user, err := app.store.Users.GetByID(ctx, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				app.unauthorizedErrorResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+
+		// the token only lasts as long as its session family, which logging
+		// out, revoking the session or resetting the password ends
+		sid, _ := claims["sid"].(string)
+		active, err := app.store.Sessions.GetActive(ctx, userID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if !active.Contains(sid) {
+			app.unauthorizedErrorResponse(w, r, errors.New("session has been revoked"))
+			return
+		}
+
+		if entry, ok := ctx.Value(accessLogCtx).(*accessLogEntry); ok {
+			entry.userID = user.ID
+		}
+
+		ctx = context.WithValue(ctx, authUserCtx, user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// checkPostOwnership lets the author of the post in the request context
+// through, as well as any user whose role is at least requiredRole.
+func (app *application) checkPostOwnership(requiredRole string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := getAuthUserFromCtx(r)
+		post := getPostFromCtx(r)
+
+		if post.UserID == user.ID {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		app.requireRole(requiredRole, next).ServeHTTP(w, r)
+	}
+}
+
+// checkCommentOwnership is the comment counterpart of checkPostOwnership.
+func (app *application) checkCommentOwnership(requiredRole string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := getAuthUserFromCtx(r)
+		comment := getCommentFromCtx(r)
+
+		if comment.UserID == user.ID {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		app.requireRole(requiredRole, next).ServeHTTP(w, r)
+	}
+}
+
+func (app *application) requireRole(requiredRole string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := getAuthUserFromCtx(r)
+
+		allowed, err := app.checkRolePrecedence(r.Context(), user, requiredRole)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		if !allowed {
+			app.forbiddenResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+func (app *application) checkRolePrecedence(ctx context.Context, user *store.User, roleName string) (bool, error) {
+	role, err := app.store.Roles.GetByName(ctx, roleName)
+	if err != nil {
+		return false, err
+	}
+
+	return user.Role.Level >= role.Level, nil
+}
+
+// RateLimiterMiddleware counts each request against the quota of the key
+// returned by key and rejects it once the quota is spent. A nil limiter
+// disables the policy; limiter errors let the request through.
+func (app *application) RateLimiterMiddleware(limiter ratelimiter.Limiter, key func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			res, err := limiter.Allow(r.Context(), key(r))
+			if err != nil {
+				app.requestLogger(r).Warnw("rate limiter unavailable", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(seconds(res.Reset)))
+
+			if !res.Allowed {
+				app.rateLimitExceededResponse(w, r, seconds(res.RetryAfter))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RealIPMiddleware replaces the remote address of requests relayed by a
+// trusted proxy with the client address the proxy reports. The forwarding
+// headers of other requests are ignored, since any client can set them.
+func (app *application) RealIPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip, ok := app.forwardedIP(r); ok {
+			r.RemoteAddr = ip.String()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// forwardedIP returns the client address reported through X-Forwarded-For or
+// X-Real-IP when the socket peer of r is a trusted proxy. X-Forwarded-For is
+// read from the right, as each proxy appends the address it got the request
+// from: the first untrusted hop is the client.
+func (app *application) forwardedIP(r *http.Request) (netip.Addr, bool) {
+	peer, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil || !app.trustedProxy(peer.Addr()) {
+		return netip.Addr{}, false
+	}
+
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				return netip.Addr{}, false
+			}
+			if i == 0 || !app.trustedProxy(ip) {
+				return ip, true
+			}
+		}
+	}
+
+	ip, err := netip.ParseAddr(r.Header.Get("X-Real-IP"))
+	return ip, err == nil
+}
+
+func (app *application) trustedProxy(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, prefix := range app.config.trustedProxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTrustedProxies parses a comma separated list of IP addresses and
+// CIDR ranges.
+func parseTrustedProxies(list string) ([]netip.Prefix, error) {
+	var proxies []netip.Prefix
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+			}
+			proxies = append(proxies, netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+// clientIP returns the client IP, as set by RealIPMiddleware.
+func clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// clientIPKey keys rate limits by the client IP.
+func clientIPKey(r *http.Request) string {
+	return "ip:" + clientIP(r)
+}
+
+// authUserKey keys rate limits by the authenticated user, falling back to the
+// client IP.
+func authUserKey(r *http.Request) string {
+	user, ok := r.Context().Value(authUserCtx).(*store.User)
+	if !ok {
+		return clientIPKey(r)
+	}
+	return "user:" + strconv.FormatInt(user.ID, 10)
+}
+
+// seconds rounds d up to whole seconds, as used by the rate limit headers.
+func seconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIPMiddleware(t *testing.T) {
+	app, _ := newTestApplication(t)
+
+	proxies, err := parseTrustedProxies("10.0.0.0/8, 192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.config.trustedProxies = proxies
+
+	var got string
+	handler := app.RealIPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = clientIP(r)
+	}))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{
+			name:       "should ignore forwarding headers from untrusted peers",
+			remoteAddr: "203.0.113.7:4321",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1", "X-Real-IP": "198.51.100.1"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "should use the peer without forwarding headers",
+			remoteAddr: "10.0.0.2:4321",
+			want:       "10.0.0.2",
+		},
+		{
+			name:       "should take the first untrusted hop from the right",
+			remoteAddr: "10.0.0.2:4321",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.7, 10.0.0.3"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "should take the leftmost hop when every hop is trusted",
+			remoteAddr: "192.0.2.1:4321",
+			headers:    map[string]string{"X-Forwarded-For": "10.0.0.4, 10.0.0.3"},
+			want:       "10.0.0.4",
+		},
+		{
+			name:       "should fall back to X-Real-IP",
+			remoteAddr: "192.0.2.1:4321",
+			headers:    map[string]string{"X-Real-IP": "203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "should ignore malformed forwarding headers",
+			remoteAddr: "10.0.0.2:4321",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.7, not-an-ip"},
+			want:       "10.0.0.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/health", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.want {
+				t.Fatalf("expected the client IP to be %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	if proxies, err := parseTrustedProxies(""); err != nil || len(proxies) != 0 {
+		t.Fatalf("expected no trusted proxies, got %v, %v", proxies, err)
+	}
+	if _, err := parseTrustedProxies("10.0.0.0/8, proxy.local"); err == nil {
+		t.Fatal("expected an error for a host name")
+	}
+}
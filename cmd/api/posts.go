@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"slices"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
@@ -20,12 +21,15 @@ type CreatePostPayload struct {
 	Title   string   `json:"title" validate:"required,max=100"`
 	Content string   `json:"content" validate:"required,max=1000"`
 	Tags    []string `json:"tags"`
+	// Visibility defaults to public
+	Visibility string `json:"visibility" validate:"omitempty,oneof=public followers mentioned"`
 }
 
 type UpdatePostPayload struct {
-	Title   *string   `json:"title" validate:"omitempty,max=100"`
-	Content *string   `json:"content" validate:"omitempty,max=1000"`
-	Tags    *[]string `json:"tags" validate:"omitempty"`
+	Title      *string   `json:"title" validate:"omitempty,max=100"`
+	Content    *string   `json:"content" validate:"omitempty,max=1000"`
+	Tags       *[]string `json:"tags" validate:"omitempty"`
+	Visibility *string   `json:"visibility" validate:"omitempty,oneof=public followers mentioned"`
 }
 
 // CreatePost godoc
@@ -37,12 +41,13 @@ type UpdatePostPayload struct {
 //	@Produce		json
 //	@Param			post	body		CreatePostPayload	true	"Post details"
 //	@Success		201		{object}	store.Post			"Post Created"
-//	@Failure		400		{object}	error				"Invalid Post Payload"
-//	@Failure		500		{object}	error				"Something went wrong"
+//	@Header			201		{string}	ETag				"Weak ETag of the post version"
+//	@Failure		400		{object}	problem				"Invalid Post Payload"
+//	@Failure		500		{object}	problem				"Something went wrong"
 //	@Security		ApiKeyAuth
 //	@Router			/posts/ [post]
 func (app *application) createPostHandler(w http.ResponseWriter, r *http.Request) {
-	user_Id := 1
+	user := getAuthUserFromCtx(r)
 	var payload CreatePostPayload
 	if err := readJSON(w, r, &payload); err != nil {
 		app.badRequestResponse(w, r, err)
@@ -54,16 +59,19 @@ func (app *application) createPostHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 	post := &store.Post{
-		Title:   payload.Title,
-		Content: payload.Content,
-		UserID:  int64(user_Id),
-		Tags:    payload.Tags,
+		Title:      payload.Title,
+		Content:    payload.Content,
+		UserID:     user.ID,
+		Tags:       payload.Tags,
+		Visibility: payload.Visibility,
 	}
 	ctx := r.Context()
 	if err := app.store.Posts.Create(ctx, post); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
+
+	w.Header().Set("ETag", versionETag(post.Version))
 	if err := app.jsonResponse(w, http.StatusCreated, post); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -73,28 +81,62 @@ func (app *application) createPostHandler(w http.ResponseWriter, r *http.Request
 // GetPost godoc
 //
 //	@Summary		Fetch Post
-//	@Description	Fetch Post details by ID
+//	@Description	Fetch Post details by ID. Posts hidden from the caller by their visibility, a block or a private author are reported as not found.
 //	@Tags			posts
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		int			true	"Post ID"
-//	@Success		200	{object}	store.Post	"Post Details"
-//	@Failure		404	{object}	error		"Post Not found"
-//	@Failure		500	{object}	error		"Something went wrong"
+//	@Param			id				path		int			true	"Post ID"
+//	@Param			If-None-Match	header		string		false	"ETag of a cached copy of the post"
+//	@Success		200				{object}	store.Post	"Post Details"
+//	@Header			200				{string}	ETag		"Weak ETag of the post with its comments and reactions"
+//	@Success		304				{object}	nil			"Post Not Modified"
+//	@Failure		400				{object}	problem		"Invalid post ID"
+//	@Failure		404				{object}	problem		"Post Not found"
+//	@Failure		500				{object}	problem		"Something went wrong"
 //	@Security		ApiKeyAuth
 //	@Router			/posts/{id} [get]
 func (app *application) getPostHandler(w http.ResponseWriter, r *http.Request) {
 	post := getPostFromCtx(r)
 
+	user := getAuthUserFromCtx(r)
 	ctx := r.Context()
 
-	comments, err := app.store.Comments.GetByPostID(ctx, post.ID)
+	// only the first page of comments is embedded, the rest is loaded
+	// through the comments endpoints
+	comments, _, err := app.store.Comments.GetByPostID(ctx, post.ID, store.CommentQuery{
+		ViewerID: user.ID,
+		Limit:    20,
+		Sort:     "desc",
+		Depth:    1,
+		Replies:  3,
+	})
 
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 	post.Comments = comments
+
+	reactions, err := app.store.Reactions.GetCounts(ctx, []int64{post.ID}, user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	post.Reactions = reactions[post.ID]
+
+	// the tag covers the embedded comments and reactions, so it can only be
+	// checked once they are loaded
+	etag, err := postETag(post, user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	if err := app.jsonResponse(w, http.StatusOK, post); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -104,27 +146,45 @@ func (app *application) getPostHandler(w http.ResponseWriter, r *http.Request) {
 // DeletePost godoc
 //
 //	@Summary		Delete Post
-//	@Description	Delete Post details by ID
+//	@Description	Move a post to the trash, from where it can be restored until it is purged
 //	@Tags			posts
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		int		true	"Post ID"
-//	@Success		204	{object}	nil		"Post Deleted"
-//	@Failure		404	{object}	error	"Post not found"
-//	@Failure		500	{object}	error	"Something went wrong"
+//	@Param			id			path		int		true	"Post ID"
+//	@Param			If-Match	header		string	false	"ETag the post must still have"
+//	@Success		204			{object}	nil		"Post Deleted"
+//	@Failure		403			{object}	problem	"Forbidden"
+//	@Failure		404			{object}	problem	"Post not found"
+//	@Failure		409			{object}	problem	"Post modified concurrently"
+//	@Failure		412			{object}	problem	"Post has been modified"
+//	@Failure		428			{object}	problem	"If-Match header required"
+//	@Failure		500			{object}	problem	"Something went wrong"
 //	@Security		ApiKeyAuth
 //	@Router			/posts/{id} [delete]
 func (app *application) deletePostHandler(w http.ResponseWriter, r *http.Request) {
 	post := getPostFromCtx(r)
 
+	if !app.checkIfMatch(w, r, post.Version, app.config.posts.requireIfMatch) {
+		return
+	}
+
+	// the version pins the delete to the post that passed the precondition,
+	// should it be updated in between
 	ctx := r.Context()
-	err := app.store.Posts.Delete(ctx, post.ID)
+	err := app.store.Posts.Delete(ctx, post.ID, post.Version)
 
 	if err != nil {
 		switch err {
 		case store.ErrNotFound:
 			app.notFoundResponse(w, r, err)
 			return
+		case store.ErrConflict:
+			if r.Header.Get("If-Match") != "" {
+				app.preconditionFailedResponse(w, r)
+				return
+			}
+			app.conflictResponse(w, r, err)
+			return
 		default:
 			app.internalServerError(w, r, err)
 			return
@@ -142,12 +202,18 @@ func (app *application) deletePostHandler(w http.ResponseWriter, r *http.Request
 //	@Tags			posts
 //	@Accept			json
 //	@Produce		json
-//	@Param			id		path		int					true	"Post ID"
-//	@Param			post	body		UpdatePostPayload	true	"Updated Post details"
-//	@Success		201		{object}	store.Post			"Post Updated"
-//	@Failure		400		{object}	error				"Invalid Post Payload"
-//	@Failure		404		{object}	error				"Post not found"
-//	@Failure		500		{object}	error				"Something went wrong"
+//	@Param			id			path		int					true	"Post ID"
+//	@Param			If-Match	header		string				false	"ETag the post must still have"
+//	@Param			post		body		UpdatePostPayload	true	"Updated Post details"
+//	@Success		201			{object}	store.Post			"Post Updated"
+//	@Header			201			{string}	ETag				"Weak ETag of the new post version"
+//	@Failure		400			{object}	problem				"Invalid Post Payload"
+//	@Failure		403			{object}	problem				"Forbidden"
+//	@Failure		404			{object}	problem				"Post not found"
+//	@Failure		409			{object}	problem				"Post modified concurrently"
+//	@Failure		412			{object}	problem				"Post has been modified"
+//	@Failure		428			{object}	problem				"If-Match header required"
+//	@Failure		500			{object}	problem				"Something went wrong"
 //	@Security		ApiKeyAuth
 //	@Router			/posts/{id} [patch]
 func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request) {
@@ -165,6 +231,12 @@ func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request
 
 	post := getPostFromCtx(r)
 
+	// the post was loaded by postsContextMiddleware, so a matching If-Match
+	// pins the update to the version the client has seen
+	if !app.checkIfMatch(w, r, post.Version, app.config.posts.requireIfMatch) {
+		return
+	}
+
 	// the reason why we use != nil is some time "" can be valid
 	// it is valid incase you want to delete title or contents or tags
 
@@ -177,15 +249,28 @@ func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request
 	if payload.Tags != nil {
 		post.Tags = *payload.Tags
 	}
+	if payload.Visibility != nil {
+		post.Visibility = *payload.Visibility
+	}
 
+	app.savePost(w, r, post)
+}
+
+// savePost stores the edits made to post as a new version and responds with
+// it, or with 412 when the client's If-Match version has been superseded.
+func (app *application) savePost(w http.ResponseWriter, r *http.Request, post *store.Post) {
 	ctx := r.Context()
 	if err := app.store.Posts.Update(ctx, post); err != nil {
-		envelop := map[string]string{
-			"error": "the post has been modified by other user, try again",
-		}
-		switch err {
-		case store.ErrNotFound:
-			writeJSON(w, http.StatusConflict, envelop)
+		switch {
+		case errors.Is(err, store.ErrConflict):
+			if r.Header.Get("If-Match") != "" {
+				app.preconditionFailedResponse(w, r)
+				return
+			}
+			app.conflictResponse(w, r, err)
+			return
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
 			return
 		default:
 			app.internalServerError(w, r, err)
@@ -194,6 +279,7 @@ func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request
 
 	}
 
+	w.Header().Set("ETag", versionETag(post.Version))
 	if err := app.jsonResponse(w, http.StatusCreated, post); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -201,31 +287,99 @@ func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request
 }
 
 func (app *application) postsContextMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		idParam := chi.URLParam(r, postURLParam)
-		postID, err := strconv.ParseInt(idParam, 10, 64)
-		if err != nil {
-			app.internalServerError(w, r, err)
-			return
-		}
+	return app.postContext(app.store.Posts.GetByID)(next)
+}
 
-		ctx := r.Context()
-		post, err := app.store.Posts.GetByID(ctx, postID)
-		if err != nil {
-			switch {
-			case errors.Is(err, store.ErrNotFound):
-				app.notFoundResponse(w, r, err)
+// postContext loads the post named in the URL with get and stores it in the
+// request context, unless the authenticated user may not see it.
+func (app *application) postContext(get func(context.Context, int64) (*store.Post, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idParam := chi.URLParam(r, postURLParam)
+			postID, err := strconv.ParseInt(idParam, 10, 64)
+			if err != nil {
+				app.badRequestResponse(w, r, err)
 				return
-			default:
+			}
+
+			ctx := r.Context()
+			post, err := get(ctx, postID)
+			if err != nil {
+				switch {
+				case errors.Is(err, store.ErrNotFound):
+					app.notFoundResponse(w, r, err)
+					return
+				default:
+					app.internalServerError(w, r, err)
+					return
+				}
+			}
+
+			visible, err := app.canSeePost(ctx, getAuthUserFromCtx(r), post)
+			if err != nil {
 				app.internalServerError(w, r, err)
 				return
 			}
+			if !visible {
+				app.notFoundResponse(w, r, store.ErrNotFound)
+				return
+			}
+
+			ctx = context.WithValue(ctx, postCtx, post)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// canSeePost reports whether viewer may see post, given its author and its
+// visibility.
+func (app *application) canSeePost(ctx context.Context, viewer *store.User, post *store.Post) (bool, error) {
+	visible, err := app.canSeePostsOf(ctx, viewer, post.UserID)
+	if err != nil || !visible || viewer.ID == post.UserID {
+		return visible, err
+	}
+
+	switch post.Visibility {
+	case store.PostFollowers:
+		rel, err := app.store.Followers.GetRelationship(ctx, post.UserID, viewer.ID)
+		if err != nil {
+			return false, err
 		}
+		return rel.IsFollowing, nil
+	case store.PostMentioned:
+		return slices.Contains(post.MentionedIDs, viewer.ID), nil
+	default:
+		return true, nil
+	}
+}
 
-		ctx = context.WithValue(ctx, postCtx, post)
+// canSeePostsOf reports whether viewer may see the posts of authorID: the
+// two must not have blocked one another, and private accounts only show
+// their posts to their followers.
+func (app *application) canSeePostsOf(ctx context.Context, viewer *store.User, authorID int64) (bool, error) {
+	if viewer.ID == authorID {
+		return true, nil
+	}
 
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+	blocked, err := app.store.Blocks.IsBlocked(ctx, authorID, viewer.ID)
+	if err != nil || blocked {
+		return false, err
+	}
+
+	author, err := app.store.Users.GetByID(ctx, authorID)
+	if err != nil {
+		return false, err
+	}
+	if !author.IsPrivate {
+		return true, nil
+	}
+
+	rel, err := app.store.Followers.GetRelationship(ctx, authorID, viewer.ID)
+	if err != nil {
+		return false, err
+	}
+	return rel.IsFollowing, nil
 }
 
 func getPostFromCtx(r *http.Request) *store.Post {
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+func TestPostLifecycle(t *testing.T) {
+	app, _ := newTestApplication(t)
+	mux := app.mount()
+
+	author := newTestUser(t, app, "alice", "user")
+	other := newTestUser(t, app, "bob", "user")
+	moderator := newTestUser(t, app, "carol", "moderator")
+	admin := newTestUser(t, app, "dave", "admin")
+
+	req := newTestRequest(t, app, http.MethodPost, "/v1/posts/", author, CreatePostPayload{
+		Title:   "Hello",
+		Content: "First post",
+		Tags:    []string{"intro"},
+	})
+	rr := executeRequest(req, mux)
+	checkResponseCode(t, http.StatusCreated, rr)
+
+	var post store.Post
+	decodeData(t, rr, &post)
+	postURL := fmt.Sprintf("/v1/posts/%d", post.ID)
+
+	t.Run("should not allow other users to update the post", func(t *testing.T) {
+		title := "Hijacked"
+		req := newTestRequest(t, app, http.MethodPatch, postURL, other, UpdatePostPayload{Title: &title})
+
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusForbidden, rr)
+	})
+
+	t.Run("should bump the version on update", func(t *testing.T) {
+		title := "Hello again"
+		req := newTestRequest(t, app, http.MethodPatch, postURL, author, UpdatePostPayload{Title: &title})
+
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusCreated, rr)
+
+		var updated store.Post
+		decodeData(t, rr, &updated)
+		if updated.Title != title || updated.Version != post.Version+1 {
+			t.Fatalf("expected title %q at version %d, got %q at version %d", title, post.Version+1, updated.Title, updated.Version)
+		}
+	})
+
+	t.Run("should let moderators update but not delete the post", func(t *testing.T) {
+		title := "Moderated"
+		req := newTestRequest(t, app, http.MethodPatch, postURL, moderator, UpdatePostPayload{Title: &title})
+		checkResponseCode(t, http.StatusCreated, executeRequest(req, mux))
+
+		req = newTestRequest(t, app, http.MethodDelete, postURL, moderator, nil)
+		checkResponseCode(t, http.StatusForbidden, executeRequest(req, mux))
+	})
+
+	t.Run("should let admins delete the post", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodDelete, postURL, admin, nil)
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+
+		req = newTestRequest(t, app, http.MethodGet, postURL, author, nil)
+		checkResponseCode(t, http.StatusNotFound, executeRequest(req, mux))
+	})
+}
+
+func TestPostPreconditions(t *testing.T) {
+	app, _ := newTestApplication(t)
+	mux := app.mount()
+
+	author := newTestUser(t, app, "alice", "user")
+
+	post := &store.Post{UserID: author.ID, Title: "Hello", Content: "First post"}
+	if err := app.store.Posts.Create(context.Background(), post); err != nil {
+		t.Fatal(err)
+	}
+	postURL := fmt.Sprintf("/v1/posts/%d", post.ID)
+
+	req := newTestRequest(t, app, http.MethodGet, postURL, author, nil)
+	rr := executeRequest(req, mux)
+	checkResponseCode(t, http.StatusOK, rr)
+	etag := rr.Header().Get("ETag")
+	if !strings.HasPrefix(etag, `W/"0-`) {
+		t.Fatalf(`expected the ETag to extend W/"0", got %q`, etag)
+	}
+
+	getPost := func(t *testing.T, user *store.User, ifNoneMatch string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		req := newTestRequest(t, app, http.MethodGet, postURL, user, nil)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		return executeRequest(req, mux)
+	}
+
+	t.Run("should not return an unmodified post", func(t *testing.T) {
+		checkResponseCode(t, http.StatusNotModified, getPost(t, author, etag))
+	})
+
+	t.Run("should return the post once its comments or reactions change", func(t *testing.T) {
+		other := newTestUser(t, app, "bob", "user")
+
+		rr := getPost(t, other, etag)
+		checkResponseCode(t, http.StatusOK, rr)
+		otherTag := rr.Header().Get("ETag")
+
+		req := newTestRequest(t, app, http.MethodPut, postURL+"/reactions/like", other, nil)
+		checkResponseCode(t, http.StatusOK, executeRequest(req, mux))
+
+		rr = getPost(t, other, otherTag)
+		checkResponseCode(t, http.StatusOK, rr)
+		otherTag = rr.Header().Get("ETag")
+
+		req = newTestRequest(t, app, http.MethodPost, postURL+"/comments", other, CreateCommentPayload{Content: "Nice post"})
+		checkResponseCode(t, http.StatusCreated, executeRequest(req, mux))
+
+		checkResponseCode(t, http.StatusOK, getPost(t, other, otherTag))
+
+		rr = getPost(t, author, etag)
+		checkResponseCode(t, http.StatusOK, rr)
+		etag = rr.Header().Get("ETag")
+	})
+
+	title := "Hello again"
+	update := func(t *testing.T, ifMatch string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		req := newTestRequest(t, app, http.MethodPatch, postURL, author, UpdatePostPayload{Title: &title})
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		return executeRequest(req, mux)
+	}
+
+	t.Run("should update a post matching If-Match", func(t *testing.T) {
+		rr := update(t, etag)
+		checkResponseCode(t, http.StatusCreated, rr)
+		if got := rr.Header().Get("ETag"); got != `W/"1"` {
+			t.Fatalf(`expected the new ETag to be W/"1", got %q`, got)
+		}
+	})
+
+	t.Run("should compare If-Match weakly", func(t *testing.T) {
+		checkResponseCode(t, http.StatusCreated, update(t, `"1"`))
+		checkResponseCode(t, http.StatusCreated, update(t, `W/"2"`))
+	})
+
+	t.Run("should reject a lost update", func(t *testing.T) {
+		checkResponseCode(t, http.StatusPreconditionFailed, update(t, etag))
+
+		req := newTestRequest(t, app, http.MethodDelete, postURL, author, nil)
+		req.Header.Set("If-Match", etag)
+		checkResponseCode(t, http.StatusPreconditionFailed, executeRequest(req, mux))
+	})
+
+	t.Run("should require If-Match when configured", func(t *testing.T) {
+		app.config.posts.requireIfMatch = true
+		defer func() { app.config.posts.requireIfMatch = false }()
+
+		checkResponseCode(t, http.StatusPreconditionRequired, update(t, ""))
+	})
+
+	t.Run("should not delete a post updated after the If-Match check", func(t *testing.T) {
+		// another request updates the post right before it is deleted
+		next := app.store
+		defer func() { app.store = next }()
+		app.store = store.Instrument(next, func(ctx context.Context, method string) (context.Context, func(error)) {
+			if method == "Posts.Delete" {
+				post, err := next.Posts.GetByID(ctx, post.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := next.Posts.Update(ctx, post); err != nil {
+					t.Fatal(err)
+				}
+			}
+			return ctx, func(error) {}
+		})
+
+		req := newTestRequest(t, app, http.MethodDelete, postURL, author, nil)
+		req.Header.Set("If-Match", `W/"3"`)
+		checkResponseCode(t, http.StatusPreconditionFailed, executeRequest(req, mux))
+
+		app.store = next
+		req = newTestRequest(t, app, http.MethodGet, postURL, author, nil)
+		checkResponseCode(t, http.StatusOK, executeRequest(req, mux))
+	})
+}
+
+func TestPostVisibility(t *testing.T) {
+	app, _ := newTestApplication(t)
+	mux := app.mount()
+
+	alice := newTestUser(t, app, "alice", "user")
+	bob := newTestUser(t, app, "bob", "user")
+	carol := newTestUser(t, app, "carol", "user")
+
+	// bob follows alice, carol doesn't
+	req := newTestRequest(t, app, http.MethodPut, fmt.Sprintf("/v1/users/%d/follow", alice.ID), bob, nil)
+	checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+
+	createPost := func(t *testing.T, content, visibility string) string {
+		t.Helper()
+
+		req := newTestRequest(t, app, http.MethodPost, "/v1/posts/", alice, CreatePostPayload{
+			Title:      "Hello",
+			Content:    content,
+			Visibility: visibility,
+		})
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusCreated, rr)
+
+		var post store.Post
+		decodeData(t, rr, &post)
+		return fmt.Sprintf("/v1/posts/%d", post.ID)
+	}
+	getPost := func(t *testing.T, url string, user *store.User, code int) {
+		t.Helper()
+
+		req := newTestRequest(t, app, http.MethodGet, url, user, nil)
+		checkResponseCode(t, code, executeRequest(req, mux))
+	}
+	comment := func(t *testing.T, url string, user *store.User, code int) {
+		t.Helper()
+
+		req := newTestRequest(t, app, http.MethodPost, url+"/comments", user, CreateCommentPayload{Content: "Nice post"})
+		checkResponseCode(t, code, executeRequest(req, mux))
+	}
+
+	t.Run("should reject unknown visibilities", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodPost, "/v1/posts/", alice, CreatePostPayload{
+			Title:      "Hello",
+			Content:    "World",
+			Visibility: "friends",
+		})
+		checkResponseCode(t, http.StatusBadRequest, executeRequest(req, mux))
+	})
+
+	t.Run("should show followers-only posts to followers", func(t *testing.T) {
+		url := createPost(t, "For my followers", store.PostFollowers)
+
+		getPost(t, url, alice, http.StatusOK)
+		getPost(t, url, bob, http.StatusOK)
+		getPost(t, url, carol, http.StatusNotFound)
+
+		comment(t, url, bob, http.StatusCreated)
+		comment(t, url, carol, http.StatusNotFound)
+	})
+
+	t.Run("should show mentioned-only posts to mentioned users", func(t *testing.T) {
+		url := createPost(t, "Just between us @carol", store.PostMentioned)
+
+		getPost(t, url, carol, http.StatusOK)
+		getPost(t, url, bob, http.StatusNotFound)
+
+		req := newTestRequest(t, app, http.MethodGet, "/v1/users/feed", bob, nil)
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr)
+
+		var feed []store.PostWithMetadata
+		decodeData(t, rr, &feed)
+		for _, p := range feed {
+			if p.Visibility == store.PostMentioned {
+				t.Fatalf("expected the mentioned-only post to be left out of bob's feed, got %+v", p)
+			}
+		}
+	})
+
+	t.Run("should keep mentioned-only posts with the user across renames", func(t *testing.T) {
+		url := createPost(t, "Only for @carol", store.PostMentioned)
+
+		rename := func(t *testing.T, user *store.User, username string) {
+			t.Helper()
+
+			req := newTestRequest(t, app, http.MethodPatch, "/v1/users/me", user, UpdateProfilePayload{Username: &username})
+			checkResponseCode(t, http.StatusOK, executeRequest(req, mux))
+		}
+
+		rename(t, carol, "caroline")
+		dave := newTestUser(t, app, "dave", "user")
+		rename(t, dave, "carol")
+
+		getPost(t, url, carol, http.StatusOK)
+		getPost(t, url, dave, http.StatusNotFound)
+	})
+
+	t.Run("should apply visibility changes", func(t *testing.T) {
+		url := createPost(t, "Public for now", "")
+		getPost(t, url, carol, http.StatusOK)
+
+		visibility := store.PostMentioned
+		content := "Now only for @bob"
+		req := newTestRequest(t, app, http.MethodPatch, url, alice, UpdatePostPayload{Visibility: &visibility, Content: &content})
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusCreated, rr)
+
+		var post store.Post
+		decodeData(t, rr, &post)
+		if len(post.Mentions) != 1 || post.Mentions[0] != bob.Username {
+			t.Fatalf("expected bob to be mentioned, got %v", post.Mentions)
+		}
+
+		getPost(t, url, carol, http.StatusNotFound)
+		getPost(t, url, bob, http.StatusOK)
+	})
+}
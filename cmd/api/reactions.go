@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sharukh010/social/internal/store"
+)
+
+const reactionKindURLParam = "kind"
+
+// reactionKinds lists the reactions a post accepts, in validator oneof form.
+const reactionKinds = "like love haha wow sad angry"
+
+// ReactToPost godoc
+//
+//	@Summary		React to a Post
+//	@Description	Adds the authenticated user's reaction of the given kind to a post
+//	@Tags			reactions
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID	path		int				true	"Post ID"
+//	@Param			kind	path		string			true	"like, love, haha, wow, sad or angry"
+//	@Success		200		{object}	store.Reaction	"Reaction"
+//	@Failure		400		{object}	problem			"Invalid reaction kind"
+//	@Failure		404		{object}	problem			"Post not found"
+//	@Failure		500		{object}	problem			"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/reactions/{kind} [put]
+func (app *application) reactToPostHandler(w http.ResponseWriter, r *http.Request) {
+	kind, ok := app.reactionKindFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	post := getPostFromCtx(r)
+	user := getAuthUserFromCtx(r)
+
+	reaction := &store.Reaction{
+		PostID: post.ID,
+		UserID: user.ID,
+		Kind:   kind,
+		User:   *user,
+	}
+
+	if err := app.store.Reactions.Add(r.Context(), reaction); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, reaction); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// RemovePostReaction godoc
+//
+//	@Summary		Remove a Reaction
+//	@Description	Removes the authenticated user's reaction of the given kind from a post
+//	@Tags			reactions
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID	path		int		true	"Post ID"
+//	@Param			kind	path		string	true	"like, love, haha, wow, sad or angry"
+//	@Success		204		{object}	nil		"Reaction Removed"
+//	@Failure		400		{object}	problem	"Invalid reaction kind"
+//	@Failure		404		{object}	problem	"Reaction not found"
+//	@Failure		500		{object}	problem	"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/reactions/{kind} [delete]
+func (app *application) removePostReactionHandler(w http.ResponseWriter, r *http.Request) {
+	kind, ok := app.reactionKindFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	post := getPostFromCtx(r)
+	user := getAuthUserFromCtx(r)
+
+	if err := app.store.Reactions.Remove(r.Context(), post.ID, user.ID, kind); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPostReactions godoc
+//
+//	@Summary		Fetch Reactions
+//	@Description	Fetch a page of the users who reacted to a post with the given kind
+//	@Tags			reactions
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID	path		int					true	"Post ID"
+//	@Param			kind	path		string				true	"like, love, haha, wow, sad or angry"
+//	@Param			limit	query		int					false	"Limit"
+//	@Param			cursor	query		string				false	"Cursor from next_cursor or prev_cursor"
+//	@Success		200		{object}	[]store.Reaction	"Reactions"
+//	@Failure		400		{object}	problem				"Invalid reaction query"
+//	@Failure		404		{object}	problem				"Post not found"
+//	@Failure		500		{object}	problem				"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/reactions/{kind} [get]
+func (app *application) getPostReactionsHandler(w http.ResponseWriter, r *http.Request) {
+	kind, ok := app.reactionKindFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	rq := store.ReactionQuery{
+		Limit: 20,
+	}
+
+	rq, err := rq.Parse(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := validate.Struct(rq); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	rq, err = rq.DecodeCursor([]byte(app.config.pagination.cursorSecret))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	post := getPostFromCtx(r)
+
+	reactions, page, err := app.store.Reactions.GetByPostID(r.Context(), post.ID, kind, rq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.paginatedJSONResponse(w, http.StatusOK, reactions, page); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+func (app *application) reactionKindFromURL(w http.ResponseWriter, r *http.Request) (string, bool) {
+	kind := chi.URLParam(r, reactionKindURLParam)
+	if err := validate.Var(kind, "oneof="+reactionKinds); err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("kind must be one of: %s", reactionKinds))
+		return "", false
+	}
+	return kind, true
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/sharukh010/social/internal/store"
+)
+
+const revisionURLParam = "version"
+
+// RevisionDiff holds a unified diff per field between two versions of a
+// post. Fields that didn't change have an empty diff.
+type RevisionDiff struct {
+	From    int    `json:"from"`
+	To      int    `json:"to"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	Tags    string `json:"tags"`
+}
+
+// GetPostRevisions godoc
+//
+//	@Summary		Fetch Post Revisions
+//	@Description	Fetch a page of the previous versions of a post, most recent first
+//	@Tags			posts
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID	path		int						true	"Post ID"
+//	@Param			limit	query		int						false	"Limit"
+//	@Param			cursor	query		string					false	"Cursor from next_cursor or prev_cursor"
+//	@Success		200		{object}	[]store.PostRevision	"Revisions"
+//	@Failure		400		{object}	problem					"Invalid revision query"
+//	@Failure		404		{object}	problem					"Post not found"
+//	@Failure		500		{object}	problem					"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/revisions [get]
+func (app *application) getPostRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	rq := store.RevisionQuery{
+		Limit: 20,
+	}
+
+	rq, err := rq.Parse(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := validate.Struct(rq); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	rq, err = rq.DecodeCursor([]byte(app.config.pagination.cursorSecret))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	post := getPostFromCtx(r)
+
+	revisions, page, err := app.store.Revisions.GetByPostID(r.Context(), post.ID, rq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.paginatedJSONResponse(w, http.StatusOK, revisions, page); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// GetPostRevision godoc
+//
+//	@Summary		Fetch Post Revision
+//	@Description	Fetch a post as it was at the given version, which may be the current one
+//	@Tags			posts
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID	path		int					true	"Post ID"
+//	@Param			version	path		int					true	"Post version"
+//	@Success		200		{object}	store.PostRevision	"Revision"
+//	@Failure		400		{object}	problem				"Invalid version"
+//	@Failure		404		{object}	problem				"Revision not found"
+//	@Failure		500		{object}	problem				"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/revisions/{version} [get]
+func (app *application) getPostRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	version, err := strconv.Atoi(chi.URLParam(r, revisionURLParam))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	rev, err := app.postVersion(r.Context(), getPostFromCtx(r), version)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, rev); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// DiffPostRevisions godoc
+//
+//	@Summary		Diff Post Revisions
+//	@Description	Compare two versions of a post as unified diffs of its title, content and tags
+//	@Tags			posts
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID	path		int				true	"Post ID"
+//	@Param			from	query		int				true	"Version to compare from"
+//	@Param			to		query		int				false	"Version to compare to, the current one by default"
+//	@Success		200		{object}	RevisionDiff	"Diff"
+//	@Failure		400		{object}	problem			"Invalid versions"
+//	@Failure		404		{object}	problem			"Revision not found"
+//	@Failure		500		{object}	problem			"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/revisions/diff [get]
+func (app *application) diffPostRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	qs := r.URL.Query()
+
+	from, err := strconv.Atoi(qs.Get("from"))
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("from must be a version: %w", err))
+		return
+	}
+	to := post.Version
+	if qs.Get("to") != "" {
+		if to, err = strconv.Atoi(qs.Get("to")); err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("to must be a version: %w", err))
+			return
+		}
+	}
+
+	ctx := r.Context()
+	var revs [2]*store.PostRevision
+	for i, version := range []int{from, to} {
+		revs[i], err = app.postVersion(ctx, post, version)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				app.notFoundResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+	}
+
+	diff, err := diffRevisions(revs[0], revs[1])
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, diff); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// RestorePostRevision godoc
+//
+//	@Summary		Restore Post Revision
+//	@Description	Restore the title, content and tags of an older version as a new version of the post
+//	@Tags			posts
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID		path		int			true	"Post ID"
+//	@Param			version		path		int			true	"Version to restore"
+//	@Param			If-Match	header		string		false	"ETag the post must still have"
+//	@Success		201			{object}	store.Post	"Post Restored"
+//	@Header			201			{string}	ETag		"Weak ETag of the new post version"
+//	@Failure		400			{object}	problem		"Invalid version"
+//	@Failure		403			{object}	problem		"Forbidden"
+//	@Failure		404			{object}	problem		"Revision not found"
+//	@Failure		409			{object}	problem		"Post modified concurrently"
+//	@Failure		412			{object}	problem		"Post has been modified"
+//	@Failure		428			{object}	problem		"If-Match header required"
+//	@Failure		500			{object}	problem		"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/revisions/{version}/restore [post]
+func (app *application) restorePostRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	user := getAuthUserFromCtx(r)
+
+	if post.UserID != user.ID {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	version, err := strconv.Atoi(chi.URLParam(r, revisionURLParam))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if version == post.Version {
+		app.badRequestResponse(w, r, errors.New("the post is already at this version"))
+		return
+	}
+
+	if !app.checkIfMatch(w, r, post.Version, app.config.posts.requireIfMatch) {
+		return
+	}
+
+	rev, err := app.store.Revisions.GetByVersion(r.Context(), post.ID, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	post.Title = rev.Title
+	post.Content = rev.Content
+	post.Tags = rev.Tags
+
+	app.savePost(w, r, post)
+}
+
+// postVersion returns post as it was at version, which is the post itself
+// for its current version and one of its revisions otherwise.
+func (app *application) postVersion(ctx context.Context, post *store.Post, version int) (*store.PostRevision, error) {
+	if version == post.Version {
+		return &store.PostRevision{
+			PostID:    post.ID,
+			Version:   post.Version,
+			Title:     post.Title,
+			Content:   post.Content,
+			Tags:      post.Tags,
+			CreatedAt: post.UpdatedAt,
+		}, nil
+	}
+
+	return app.store.Revisions.GetByVersion(ctx, post.ID, version)
+}
+
+func diffRevisions(from, to *store.PostRevision) (*RevisionDiff, error) {
+	diff := &RevisionDiff{From: from.Version, To: to.Version}
+
+	fromName := "v" + strconv.Itoa(from.Version)
+	toName := "v" + strconv.Itoa(to.Version)
+
+	for _, field := range []struct {
+		from, to string
+		diff     *string
+	}{
+		{from.Title, to.Title, &diff.Title},
+		{from.Content, to.Content, &diff.Content},
+		{strings.Join(from.Tags, "\n"), strings.Join(to.Tags, "\n"), &diff.Tags},
+	} {
+		d, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(field.from),
+			B:        difflib.SplitLines(field.to),
+			FromFile: fromName,
+			ToFile:   toName,
+			Context:  3,
+		})
+		if err != nil {
+			return nil, err
+		}
+		*field.diff = d
+	}
+
+	return diff, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+func TestPostRevisions(t *testing.T) {
+	app, _ := newTestApplication(t)
+	mux := app.mount()
+
+	author := newTestUser(t, app, "alice", "user")
+	other := newTestUser(t, app, "bob", "user")
+
+	req := newTestRequest(t, app, http.MethodPost, "/v1/posts/", author, CreatePostPayload{
+		Title:   "Hello",
+		Content: "line one\nline two",
+		Tags:    []string{"intro"},
+	})
+	rr := executeRequest(req, mux)
+	checkResponseCode(t, http.StatusCreated, rr)
+
+	var post store.Post
+	decodeData(t, rr, &post)
+	postURL := fmt.Sprintf("/v1/posts/%d", post.ID)
+
+	for _, content := range []string{"line one\nline 2", "line one\nline 2\nline three"} {
+		req := newTestRequest(t, app, http.MethodPatch, postURL, author, UpdatePostPayload{Content: &content})
+		checkResponseCode(t, http.StatusCreated, executeRequest(req, mux))
+	}
+
+	t.Run("should list the previous versions", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodGet, postURL+"/revisions", author, nil)
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr)
+
+		var revisions []store.PostRevision
+		decodeData(t, rr, &revisions)
+		if len(revisions) != 2 || revisions[0].Version != 1 || revisions[1].Version != 0 {
+			t.Fatalf("expected versions 1 and 0, got %+v", revisions)
+		}
+		if revisions[1].Content != "line one\nline two" {
+			t.Fatalf("expected the original content, got %q", revisions[1].Content)
+		}
+	})
+
+	t.Run("should diff two versions", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodGet, postURL+"/revisions/diff?from=0", author, nil)
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr)
+
+		var diff RevisionDiff
+		decodeData(t, rr, &diff)
+		if diff.From != 0 || diff.To != 2 || diff.Title != "" || diff.Tags != "" {
+			t.Fatalf("unexpected diff %+v", diff)
+		}
+		for _, line := range []string{"-line two", "+line 2", "+line three"} {
+			if !strings.Contains(diff.Content, line+"\n") {
+				t.Errorf("expected the content diff to contain %q, got:\n%s", line, diff.Content)
+			}
+		}
+	})
+
+	t.Run("should only let the author restore a revision", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodPost, postURL+"/revisions/0/restore", other, nil)
+		checkResponseCode(t, http.StatusForbidden, executeRequest(req, mux))
+
+		req = newTestRequest(t, app, http.MethodPost, postURL+"/revisions/0/restore", author, nil)
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusCreated, rr)
+
+		var restored store.Post
+		decodeData(t, rr, &restored)
+		if restored.Version != 3 || restored.Content != "line one\nline two" {
+			t.Fatalf("expected the original content at version 3, got %q at version %d", restored.Content, restored.Version)
+		}
+	})
+
+	t.Run("should return 404 for unknown versions", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodGet, postURL+"/revisions/42", author, nil)
+		checkResponseCode(t, http.StatusNotFound, executeRequest(req, mux))
+	})
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+// Search godoc
+//
+//	@Summary		Search
+//	@Description	Full-text search over posts, users or comments, ranked by relevance
+//	@Tags			search
+//	@Accept			json
+//	@Produce		json
+//	@Param			q		query		string						true	"Search terms, websearch syntax (quotes, OR, -)"
+//	@Param			type	query		string						false	"posts (default), users or comments"
+//	@Param			limit	query		int							false	"Limit"
+//	@Param			cursor	query		string						false	"Cursor from next_cursor or prev_cursor"
+//	@Success		200		{object}	[]store.PostSearchResult	"Search results"
+//	@Failure		400		{object}	problem						"Invalid search query"
+//	@Failure		401		{object}	problem						"Unauthorized"
+//	@Failure		500		{object}	problem						"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/search [get]
+func (app *application) searchHandler(w http.ResponseWriter, r *http.Request) {
+	sq := store.SearchQuery{
+		Type:  "posts",
+		Limit: 10,
+	}
+
+	sq, err := sq.Parse(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := validate.Struct(sq); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	sq, err = sq.DecodeCursor([]byte(app.config.pagination.cursorSecret))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	sq.ViewerID = getAuthUserFromCtx(r).ID
+
+	ctx := r.Context()
+
+	var (
+		results any
+		page    store.Page
+	)
+	switch sq.Type {
+	case "users":
+		results, page, err = app.store.Search.Users(ctx, sq)
+	case "comments":
+		results, page, err = app.store.Search.Comments(ctx, sq)
+	default:
+		results, page, err = app.store.Search.Posts(ctx, sq)
+	}
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.paginatedJSONResponse(w, http.StatusOK, results, page); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
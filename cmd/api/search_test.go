@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+func TestSearchPosts(t *testing.T) {
+	app, _ := newTestApplication(t)
+	mux := app.mount()
+
+	alice := newTestUser(t, app, "alice", "user")
+
+	req := newTestRequest(t, app, http.MethodPost, "/v1/posts/", alice, CreatePostPayload{
+		Title:   "Hello",
+		Content: `gopher <script>alert("hi")</script>`,
+	})
+	checkResponseCode(t, http.StatusCreated, executeRequest(req, mux))
+
+	req = newTestRequest(t, app, http.MethodGet, "/v1/search?q=gopher", alice, nil)
+	rr := executeRequest(req, mux)
+	checkResponseCode(t, http.StatusOK, rr)
+
+	var results []store.PostSearchResult
+	decodeData(t, rr, &results)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+
+	t.Run("should escape the content of snippets", func(t *testing.T) {
+		snippet := results[0].Snippet
+		if strings.Contains(snippet, "<script>") || !strings.Contains(snippet, "&lt;script&gt;") {
+			t.Fatalf("expected the content to be escaped, got %q", snippet)
+		}
+		if !strings.Contains(snippet, "<mark>gopher</mark>") {
+			t.Fatalf("expected the match to be marked, got %q", snippet)
+		}
+	})
+
+	t.Run("should return the visibility of posts", func(t *testing.T) {
+		if results[0].Visibility != store.PostPublic {
+			t.Fatalf("expected a public post, got %q", results[0].Visibility)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sharukh010/social/internal/store"
+)
+
+// GetUserSessions godoc
+//
+//	@Summary		List sessions
+//	@Description	Lists the active sessions of the authenticated user
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	[]store.Session	"Active Sessions"
+//	@Failure		401	{object}	problem			"Unauthorized"
+//	@Failure		500	{object}	problem			"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/sessions [get]
+func (app *application) getUserSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getAuthUserFromCtx(r)
+
+	sessions, err := app.store.Sessions.GetByUserID(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, sessions); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// RevokeUserSession godoc
+//
+//	@Summary		Revoke a session
+//	@Description	Revokes one of the authenticated user's sessions by ID
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			sessionID	path		int		true	"Session ID"
+//	@Success		204			{object}	nil		"Session Revoked"
+//	@Failure		401			{object}	problem	"Unauthorized"
+//	@Failure		404			{object}	problem	"Session not found"
+//	@Failure		500			{object}	problem	"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/sessions/{sessionID} [delete]
+func (app *application) revokeUserSessionHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := strconv.ParseInt(chi.URLParam(r, "sessionID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getAuthUserFromCtx(r)
+
+	if err := app.store.Sessions.RevokeByID(r.Context(), user.ID, sessionID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
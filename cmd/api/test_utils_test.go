@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/sharukh010/social/internal/auth"
+	"github.com/sharukh010/social/internal/mailer"
+	"github.com/sharukh010/social/internal/store"
+	"github.com/sharukh010/social/internal/store/memstore"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+)
+
+// testMailbox collects the emails sent by the sandbox mailer of a test
+// application.
+type testMailbox struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (m *testMailbox) Write(p []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.buf.Write(p)
+}
+
+var mailTokenRe = regexp.MustCompile(`(?:use this token: |/password/reset/)([0-9a-f-]{36})`)
+
+// lastToken returns the token from the most recent invitation or password
+// reset email.
+func (m *testMailbox) lastToken(t *testing.T) string {
+	t.Helper()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	matches := mailTokenRe.FindAllStringSubmatch(m.buf.String(), -1)
+	if len(matches) == 0 {
+		t.Fatal("no token found in the sent emails")
+	}
+	return matches[len(matches)-1][1]
+}
+
+func newTestApplication(t *testing.T) (*application, *testMailbox) {
+	t.Helper()
+
+	cfg := config{
+		addr:   ":8080",
+		apiURL: "localhost:8080",
+		mail: mailConfig{
+			exp:      time.Hour,
+			resetExp: time.Hour,
+		},
+		auth: authConfig{
+			basic: basicConfig{
+				user: "admin",
+				pass: "test",
+			},
+			token: tokenConfig{
+				secret:     "test",
+				exp:        time.Minute * 15,
+				refreshExp: time.Hour,
+				aud:        "test",
+				iss:        "test",
+			},
+		},
+		comments: commentsConfig{
+			maxDepth: 5,
+		},
+		trash: trashConfig{
+			retention: time.Hour,
+		},
+		users: usersConfig{
+			usernameCooldown: time.Hour,
+		},
+		pagination: paginationConfig{
+			cursorSecret: "test",
+		},
+		env: "test",
+	}
+
+	mailbox := &testMailbox{}
+
+	metrics := newMetrics(nil)
+
+	app := &application{
+		config:        cfg,
+		store:         store.Instrument(memstore.New(), metrics.storeHook),
+		logger:        zap.NewNop().Sugar(),
+		authenticator: auth.NewJWTAuthenticator(cfg.auth.token.secret, cfg.auth.token.aud, cfg.auth.token.iss),
+		mailer:        mailer.NewSandbox(mailbox),
+		metrics:       metrics,
+		tracer:        noop.NewTracerProvider().Tracer(tracerName),
+	}
+
+	return app, mailbox
+}
+
+// newTestUser creates an active user with the given role.
+func newTestUser(t *testing.T, app *application, username, role string) *store.User {
+	t.Helper()
+
+	user := &store.User{
+		Username: username,
+		Email:    username + "@example.com",
+		Role:     store.Role{Name: role},
+	}
+	if err := user.Password.Set("password"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	plainToken := uuid.New().String()
+	if err := app.store.Users.CreateAndInvite(ctx, user, hashToken(plainToken), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.store.Users.Activate(ctx, plainToken); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.store.Users.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// newTestRequest builds a request with body encoded as JSON, authenticated
+// as user unless user is nil.
+func newTestRequest(t *testing.T, app *application, method, url string, user *store.User, body any) *http.Request {
+	t.Helper()
+
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, url, r)
+	if user != nil {
+		now := time.Now()
+		token, err := app.authenticator.GenerateToken(jwt.MapClaims{
+			"sub": strconv.FormatInt(user.ID, 10),
+			"sid": testSession(t, app, user),
+			"exp": now.Add(app.config.auth.token.exp).Unix(),
+			"iat": now.Unix(),
+			"nbf": now.Unix(),
+			"iss": app.config.auth.token.iss,
+			"aud": app.config.auth.token.aud,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return req
+}
+
+// testSession returns an active session family of user, starting one if
+// there is none, for the sid claim of test access tokens.
+func testSession(t *testing.T, app *application, user *store.User) string {
+	t.Helper()
+
+	ctx := context.Background()
+	active, err := app.store.Sessions.GetActive(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active.FamilyIDs) > 0 {
+		return active.FamilyIDs[0]
+	}
+
+	session := &store.Session{
+		UserID:   user.ID,
+		FamilyID: uuid.New().String(),
+		Token:    hashToken(uuid.New().String()),
+		Expiry:   time.Now().Add(app.config.auth.token.refreshExp),
+	}
+	if err := app.store.Sessions.Create(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+	return session.FamilyID
+}
+
+func executeRequest(req *http.Request, mux http.Handler) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	return rr
+}
+
+func checkResponseCode(t *testing.T, expected int, rr *httptest.ResponseRecorder) {
+	t.Helper()
+
+	if rr.Code != expected {
+		t.Fatalf("expected the response code to be %d and we got %d: %s", expected, rr.Code, rr.Body.String())
+	}
+}
+
+// decodeData decodes the data envelope of a JSON response into v.
+func decodeData(t *testing.T, rr *httptest.ResponseRecorder, v any) {
+	t.Helper()
+
+	envelope := struct {
+		Data any `json:"data"`
+	}{Data: v}
+	if err := json.NewDecoder(rr.Body).Decode(&envelope); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/sharukh010/social/internal/store"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	app, _ := newTestApplication(t)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	app.tracer = tp.Tracer(tracerName)
+	app.store = store.Instrument(app.store, traceStoreHook(app.tracer))
+	mux := app.mount()
+
+	alice := newTestUser(t, app, "alice", "user")
+	exporter.Reset()
+
+	req := newTestRequest(t, app, http.MethodGet, fmt.Sprintf("/v1/users/%d", alice.ID), alice, nil)
+	checkResponseCode(t, http.StatusOK, executeRequest(req, mux))
+
+	spans := exporter.GetSpans()
+	var root *tracetest.SpanStub
+	for i := range spans {
+		if !spans[i].Parent.IsValid() {
+			root = &spans[i]
+		}
+	}
+	if root == nil || root.Name != "GET /v1/users/{userID}" {
+		t.Fatalf("expected a request span named after the route, got %+v", spans)
+	}
+
+	var queries int
+	for _, s := range spans {
+		if s.Parent.SpanID() == root.SpanContext.SpanID() && s.Name == "Users.GetByID" {
+			queries++
+		}
+	}
+	if queries == 0 {
+		t.Fatal("expected the store queries to be children of the request span")
+	}
+}
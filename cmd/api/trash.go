@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+var errRestoreExpired = errors.New("not found in the trash, it may have been purged")
+
+// RestorePost godoc
+//
+//	@Summary		Restore Post
+//	@Description	Take a deleted post out of the trash, within the retention period
+//	@Tags			posts
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID	path		int			true	"Post ID"
+//	@Success		200		{object}	store.Post	"Post Restored"
+//	@Header			200		{string}	ETag		"Weak ETag of the post version"
+//	@Failure		403		{object}	problem		"Forbidden"
+//	@Failure		404		{object}	problem		"Post not found in the trash"
+//	@Failure		500		{object}	problem		"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/restore [post]
+func (app *application) restorePostHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+
+	if err := app.store.Posts.Restore(r.Context(), post.ID, app.restoreWindow()); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, errRestoreExpired)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	post.DeletedAt = nil
+
+	w.Header().Set("ETag", versionETag(post.Version))
+	if err := app.jsonResponse(w, http.StatusOK, post); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// RestoreComment godoc
+//
+//	@Summary		Restore Comment
+//	@Description	Take a deleted comment, and the replies hidden with it, out of the trash within the retention period
+//	@Tags			comments
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID		path		int				true	"Post ID"
+//	@Param			commentID	path		int				true	"Comment ID"
+//	@Success		200			{object}	store.Comment	"Comment Restored"
+//	@Failure		403			{object}	problem			"Forbidden"
+//	@Failure		404			{object}	problem			"Comment not found in the trash"
+//	@Failure		500			{object}	problem			"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/comments/{commentID}/restore [post]
+func (app *application) restoreCommentHandler(w http.ResponseWriter, r *http.Request) {
+	comment := getCommentFromCtx(r)
+	ctx := r.Context()
+
+	if err := app.store.Comments.Restore(ctx, comment.ID, app.restoreWindow()); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, errRestoreExpired)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	comment, err := app.store.Comments.GetByID(ctx, comment.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, comment); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+func (app *application) trashedPostsContextMiddleware(next http.Handler) http.Handler {
+	return app.postContext(app.store.Posts.GetTrashed)(next)
+}
+
+func (app *application) trashedCommentsContextMiddleware(next http.Handler) http.Handler {
+	return app.commentContext(app.store.Comments.GetTrashed)(next)
+}
+
+// restoreWindow returns the earliest deletion time that can still be
+// restored.
+func (app *application) restoreWindow() time.Time {
+	return time.Now().Add(-app.config.trash.retention)
+}
+
+// purgeTrash permanently deletes the posts and comments that have been in
+// the trash for longer than the retention period, every purge interval until
+// ctx is done.
+func (app *application) purgeTrash(ctx context.Context) {
+	if app.config.trash.purgeInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(app.config.trash.purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			app.purgeTrashOnce(ctx)
+		}
+	}
+}
+
+func (app *application) purgeTrashOnce(ctx context.Context) {
+	before := app.restoreWindow()
+
+	posts, err := app.store.Posts.Purge(ctx, before)
+	if err != nil {
+		app.logger.Errorw("purging posts from the trash", "error", err)
+		return
+	}
+
+	comments, err := app.store.Comments.Purge(ctx, before)
+	if err != nil {
+		app.logger.Errorw("purging comments from the trash", "error", err)
+		return
+	}
+
+	if posts > 0 || comments > 0 {
+		app.logger.Infow("trash purged", "posts", posts, "comments", comments)
+	}
+}
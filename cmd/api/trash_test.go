@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+func TestTrash(t *testing.T) {
+	app, _ := newTestApplication(t)
+	mux := app.mount()
+
+	author := newTestUser(t, app, "alice", "user")
+	other := newTestUser(t, app, "bob", "user")
+
+	req := newTestRequest(t, app, http.MethodPost, "/v1/posts/", author, CreatePostPayload{
+		Title:   "Hello",
+		Content: "World",
+	})
+	rr := executeRequest(req, mux)
+	checkResponseCode(t, http.StatusCreated, rr)
+
+	var post store.Post
+	decodeData(t, rr, &post)
+	postURL := fmt.Sprintf("/v1/posts/%d", post.ID)
+
+	req = newTestRequest(t, app, http.MethodPost, postURL+"/comments", other, CreateCommentPayload{Content: "Nice post"})
+	rr = executeRequest(req, mux)
+	checkResponseCode(t, http.StatusCreated, rr)
+
+	var comment store.Comment
+	decodeData(t, rr, &comment)
+	commentURL := fmt.Sprintf("%s/comments/%d", postURL, comment.ID)
+
+	countComments := func(t *testing.T) int {
+		t.Helper()
+
+		req := newTestRequest(t, app, http.MethodGet, postURL+"/comments", author, nil)
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr)
+
+		var comments []store.Comment
+		decodeData(t, rr, &comments)
+		return len(comments)
+	}
+
+	t.Run("should hide and restore a deleted comment", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodDelete, commentURL, other, nil)
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+
+		if n := countComments(t); n != 0 {
+			t.Fatalf("expected the deleted comment to be hidden, got %d comments", n)
+		}
+
+		req = newTestRequest(t, app, http.MethodPost, commentURL+"/restore", author, nil)
+		checkResponseCode(t, http.StatusForbidden, executeRequest(req, mux))
+
+		req = newTestRequest(t, app, http.MethodPost, commentURL+"/restore", other, nil)
+		checkResponseCode(t, http.StatusOK, executeRequest(req, mux))
+
+		if n := countComments(t); n != 1 {
+			t.Fatalf("expected the restored comment, got %d comments", n)
+		}
+
+		req = newTestRequest(t, app, http.MethodPost, commentURL+"/restore", other, nil)
+		checkResponseCode(t, http.StatusNotFound, executeRequest(req, mux))
+	})
+
+	t.Run("should hide and restore a deleted post with its comments", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodDelete, postURL, author, nil)
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+
+		req = newTestRequest(t, app, http.MethodGet, postURL, author, nil)
+		checkResponseCode(t, http.StatusNotFound, executeRequest(req, mux))
+
+		req = newTestRequest(t, app, http.MethodGet, "/v1/users/feed", author, nil)
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr)
+
+		var feed []store.PostWithMetadata
+		decodeData(t, rr, &feed)
+		if len(feed) != 0 {
+			t.Fatalf("expected the deleted post to be left out of the feed, got %d posts", len(feed))
+		}
+
+		req = newTestRequest(t, app, http.MethodPost, postURL+"/restore", other, nil)
+		checkResponseCode(t, http.StatusForbidden, executeRequest(req, mux))
+
+		req = newTestRequest(t, app, http.MethodPost, postURL+"/restore", author, nil)
+		checkResponseCode(t, http.StatusOK, executeRequest(req, mux))
+
+		if n := countComments(t); n != 1 {
+			t.Fatalf("expected the comment to come back with the post, got %d comments", n)
+		}
+	})
+
+	t.Run("should purge the trash after the retention period", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodDelete, postURL, author, nil)
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+
+		// everything deleted so far is past a negative retention period
+		app.config.trash.retention = -time.Minute
+		app.purgeTrashOnce(context.Background())
+
+		req = newTestRequest(t, app, http.MethodPost, postURL+"/restore", author, nil)
+		checkResponseCode(t, http.StatusNotFound, executeRequest(req, mux))
+
+		if _, err := app.store.Comments.GetTrashed(context.Background(), comment.ID); err != store.ErrNotFound {
+			t.Fatalf("expected the comment to be purged with the post, got %v", err)
+		}
+	})
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/sharukh010/social/internal/store"
@@ -13,30 +15,137 @@ type userKey string
 
 const userCtx userKey = "user"
 
+const authUserCtx userKey = "authUser"
+
 const userURLParam = "userID"
 
-type FollowRequest struct {
-	UserID int64 `json:"user_id"`
+// UserProfile is a user with their follow counts and, when someone else is
+// viewing it, how the two of them follow each other. The email is left out
+// for other viewers.
+type UserProfile struct {
+	*store.User
+	store.FollowStats
+	Relationship *store.Relationship `json:"relationship,omitempty"`
 }
 
 // GetUser godoc
 //
 //	@Summary		Fetches a user profile
-//	@Description	Fetches a user profile by ID
+//	@Description	Fetches a user profile by ID, with follow counts and the relationship to the authenticated user
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
 //	@Param			id	path		int			true	"User ID"
-//	@Success		200	{object}	store.User	"User Details"
-//	@Failure		404	{object}	error		"User not found"
-//	@Failure		500	{object}	error		"Something went wrong"
+//	@Success		200	{object}	UserProfile	"User Details"
+//	@Failure		400	{object}	problem		"Invalid user ID"
+//	@Failure		404	{object}	problem		"User not found"
+//	@Failure		500	{object}	problem		"Something went wrong"
 //	@Security		ApiKeyAuth
 //	@Router			/users/{id} [get]
 func (app *application) getUserHandler(w http.ResponseWriter, r *http.Request) {
 
 	user := getUserFromCtx(r)
+	viewer := getAuthUserFromCtx(r)
+	ctx := r.Context()
 
-	if err := app.jsonResponse(w, http.StatusOK, user); err != nil {
+	stats, err := app.store.Followers.GetStats(ctx, user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	profile := UserProfile{User: user, FollowStats: *stats}
+
+	if viewer.ID != user.ID {
+		public := *user
+		public.Email = ""
+		public.UsernameChangedAt = nil
+		profile.User = &public
+
+		profile.Relationship, err = app.store.Followers.GetRelationship(ctx, user.ID, viewer.ID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, profile); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+type UpdateProfilePayload struct {
+	Username    *string `json:"username" validate:"omitempty,min=1,max=100,username"`
+	DisplayName *string `json:"display_name" validate:"omitempty,max=100"`
+	Bio         *string `json:"bio" validate:"omitempty,max=500"`
+	// Website and AvatarURL are http(s) URLs, or empty to clear them
+	Website   *string `json:"website" validate:"omitempty,max=255,len=0|http_url"`
+	Location  *string `json:"location" validate:"omitempty,max=100"`
+	AvatarURL *string `json:"avatar_url" validate:"omitempty,max=255,len=0|http_url"`
+}
+
+// UpdateUserProfile godoc
+//
+//	@Summary		Update your profile
+//	@Description	Update the authenticated user's username and profile details. Fields left out are kept, and the username can only be changed once per cooldown.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		UpdateProfilePayload	true	"Profile details"
+//	@Success		200		{object}	store.User				"Profile Updated"
+//	@Failure		400		{object}	problem					"Invalid payload or username changed too recently"
+//	@Failure		401		{object}	problem					"Unauthorized"
+//	@Failure		409		{object}	problem					"Username taken"
+//	@Failure		500		{object}	problem					"Something went wrong"
+//	@Security		ApiKeyAuth
+//	@Router			/users/me [patch]
+func (app *application) updateUserProfileHandler(w http.ResponseWriter, r *http.Request) {
+	var payload UpdateProfilePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// the authenticated user may be shared with the cache, so edit a copy
+	user := *getAuthUserFromCtx(r)
+
+	if payload.Username != nil {
+		user.Username = *payload.Username
+	}
+	if payload.DisplayName != nil {
+		user.DisplayName = *payload.DisplayName
+	}
+	if payload.Bio != nil {
+		user.Bio = *payload.Bio
+	}
+	if payload.Website != nil {
+		user.Website = *payload.Website
+	}
+	if payload.Location != nil {
+		user.Location = *payload.Location
+	}
+	if payload.AvatarURL != nil {
+		user.AvatarURL = *payload.AvatarURL
+	}
+
+	changedBefore := time.Now().Add(-app.config.users.usernameCooldown)
+	if err := app.store.Users.UpdateProfile(r.Context(), &user, changedBefore); err != nil {
+		switch err {
+		case store.ErrUsernameTaken:
+			app.alreadyExistsResponse(w, r, err)
+		case store.ErrUsernameCooldown:
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, &user); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -45,35 +154,46 @@ func (app *application) getUserHandler(w http.ResponseWriter, r *http.Request) {
 // FollowUser godoc
 //
 //	@Summary		Follow a user
-//	@Description	Follow a user by ID
+//	@Description	Follow a user by ID. Following a private account sends it a follow request to approve instead.
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		int				true	"User ID"
-//	@Param			id	body		FollowRequest	true	"User ID"
-//	@Success		204	{object}	nil				"Followed User"
-//	@Failure		400	{object}	error			"Invalid Follow Payload"
-//	@Failure		404	{object}	error			"User not found"
-//	@Failure		500	{object}	error			"Something went wrong"
+//	@Param			id	path		int					true	"User ID"
+//	@Success		202	{object}	store.FollowRequest	"Follow Request Sent"
+//	@Success		204	{object}	nil					"Followed User"
+//	@Failure		400	{object}	problem				"Invalid Follow Payload"
+//	@Failure		401	{object}	problem				"Unauthorized"
+//	@Failure		403	{object}	problem				"Blocked"
+//	@Failure		404	{object}	problem				"User not found"
+//	@Failure		409	{object}	problem				"Already following"
+//	@Failure		500	{object}	problem				"Something went wrong"
 //	@Security		ApiKeyAuth
 //	@Router			/users/{id}/follow [put]
 func (app *application) followUserHandler(w http.ResponseWriter, r *http.Request) {
-	followerUser := getUserFromCtx(r)
-	payload := &FollowRequest{}
-	//TODO: revert back to auth userID from ctx
-	err := readJSON(w, r, payload)
-	if err != nil {
-		app.internalServerError(w, r, err)
+	followerUser := getAuthUserFromCtx(r)
+	followedUser := getUserFromCtx(r)
+
+	if followerUser.ID == followedUser.ID {
+		app.badRequestResponse(w, r, errors.New("you cannot follow yourself"))
 		return
 	}
-	if followerUser.ID == payload.UserID {
-		app.internalServerError(w, r, err)
+	ctx := r.Context()
+
+	if followedUser.IsPrivate {
+		app.requestFollow(w, r, followerUser, followedUser)
 		return
 	}
-	ctx := r.Context()
-	err = app.store.Users.Follow(ctx, followerUser.ID, payload.UserID)
+
+	err := app.store.Users.Follow(ctx, followerUser.ID, followedUser.ID)
 	if err != nil {
-		app.internalServerError(w, r, err)
+		switch err {
+		case store.ErrBlocked:
+			app.forbiddenResponse(w, r)
+		case store.ErrAlreadyFollowing:
+			app.alreadyExistsResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
 		return
 	}
 	if err := app.jsonResponse(w, http.StatusNoContent, nil); err != nil {
@@ -90,26 +210,20 @@ func (app *application) followUserHandler(w http.ResponseWriter, r *http.Request
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		int				true	"User ID"
-//	@Param			id	body		FollowRequest	true	"User ID"
-//	@Success		204	{object}	nil				"Unfollowed User"
-//	@Failure		400	{object}	error			"Invalid Unfollow Payload"
-//	@Failure		404	{object}	error			"User not found"
-//	@Failure		500	{object}	error			"Something went wrong"
+//	@Param			id	path		int		true	"User ID"
+//	@Success		204	{object}	nil		"Unfollowed User"
+//	@Failure		400	{object}	problem	"Invalid Unfollow Payload"
+//	@Failure		401	{object}	problem	"Unauthorized"
+//	@Failure		404	{object}	problem	"User not found"
+//	@Failure		500	{object}	problem	"Something went wrong"
 //	@Security		ApiKeyAuth
 //	@Router			/users/{id}/unfollow [put]
 func (app *application) unfollowUserHandler(w http.ResponseWriter, r *http.Request) {
-	unFollowerUser := getUserFromCtx(r)
-	//TODO: revert back to auth userID from ctx
-	payload := &FollowRequest{}
-	err := readJSON(w, r, payload)
-	if err != nil {
-		app.internalServerError(w, r, err)
-		return
-	}
+	unFollowerUser := getAuthUserFromCtx(r)
+	unFollowedUser := getUserFromCtx(r)
 
 	ctx := r.Context()
-	err = app.store.Users.UnFollow(ctx, unFollowerUser.ID, payload.UserID)
+	err := app.store.Users.UnFollow(ctx, unFollowerUser.ID, unFollowedUser.ID)
 
 	if err != nil {
 		switch err {
@@ -138,14 +252,14 @@ func (app *application) unfollowUserHandler(w http.ResponseWriter, r *http.Reque
 //	@Produce		json
 //	@Param			token	path		string	true	"token"
 //	@Success		204		{object}	nil		"User Activated"
-//	@Failure		404		{object}	error	"User not found"
-//	@Failure		500		{object}	error	"Something went wrong"
+//	@Failure		404		{object}	problem	"User not found"
+//	@Failure		500		{object}	problem	"Something went wrong"
 //	@Security		ApiKeyAuth
 //	@Router			/users/activate/{token} [put]
 func (app *application) activateUserHandler(w http.ResponseWriter, r *http.Request) {
 	token := chi.URLParam(r, "token")
 
-	if err := app.store.Users.Activate(r.Context(), token); err != nil {
+	if _, err := app.store.Users.Activate(r.Context(), token); err != nil {
 		switch err {
 		case store.ErrNotFound:
 			app.notFoundResponse(w, r, err)
@@ -167,7 +281,7 @@ func (app *application) userContextMiddleware(next http.Handler) http.Handler {
 		paramID := chi.URLParam(r, userURLParam)
 		userID, err := strconv.ParseInt(paramID, 10, 64)
 		if err != nil {
-			app.internalServerError(w, r, err)
+			app.badRequestResponse(w, r, err)
 			return
 		}
 		ctx := r.Context()
@@ -192,3 +306,8 @@ func getUserFromCtx(r *http.Request) *store.User {
 	user, _ := r.Context().Value(userCtx).(*store.User)
 	return user
 }
+
+func getAuthUserFromCtx(r *http.Request) *store.User {
+	user, _ := r.Context().Value(authUserCtx).(*store.User)
+	return user
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+func TestGetUser(t *testing.T) {
+	app, _ := newTestApplication(t)
+	mux := app.mount()
+
+	user := newTestUser(t, app, "alice", "user")
+
+	t.Run("should not allow unauthenticated requests", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodGet, fmt.Sprintf("/v1/users/%d", user.ID), nil, nil)
+
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusUnauthorized, rr)
+	})
+
+	t.Run("should allow authenticated requests", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodGet, fmt.Sprintf("/v1/users/%d", user.ID), user, nil)
+
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr)
+	})
+
+	t.Run("should return not found for missing users", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodGet, "/v1/users/999", user, nil)
+
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusNotFound, rr)
+	})
+}
+
+func TestFollowUser(t *testing.T) {
+	app, _ := newTestApplication(t)
+	mux := app.mount()
+
+	alice := newTestUser(t, app, "alice", "user")
+	bob := newTestUser(t, app, "bob", "user")
+
+	t.Run("should not allow following yourself", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodPut, fmt.Sprintf("/v1/users/%d/follow", alice.ID), alice, nil)
+
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusBadRequest, rr)
+	})
+
+	t.Run("should follow and unfollow a user", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodPut, fmt.Sprintf("/v1/users/%d/follow", bob.ID), alice, nil)
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+
+		req = newTestRequest(t, app, http.MethodPut, fmt.Sprintf("/v1/users/%d/follow", bob.ID), alice, nil)
+		checkResponseCode(t, http.StatusConflict, executeRequest(req, mux))
+
+		req = newTestRequest(t, app, http.MethodPut, fmt.Sprintf("/v1/users/%d/unfollow", bob.ID), alice, nil)
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+
+		req = newTestRequest(t, app, http.MethodPut, fmt.Sprintf("/v1/users/%d/unfollow", bob.ID), alice, nil)
+		checkResponseCode(t, http.StatusNotFound, executeRequest(req, mux))
+	})
+}
+
+func TestFollowLists(t *testing.T) {
+	app, _ := newTestApplication(t)
+	mux := app.mount()
+
+	alice := newTestUser(t, app, "alice", "user")
+	bob := newTestUser(t, app, "bob", "user")
+	carol := newTestUser(t, app, "carol", "user")
+
+	for _, f := range []struct{ follower, followed *store.User }{
+		{alice, bob},
+		{alice, carol},
+		{bob, alice},
+	} {
+		req := newTestRequest(t, app, http.MethodPut, fmt.Sprintf("/v1/users/%d/follow", f.followed.ID), f.follower, nil)
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+	}
+
+	usernames := func(t *testing.T, url string) []string {
+		t.Helper()
+
+		req := newTestRequest(t, app, http.MethodGet, url, alice, nil)
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr)
+
+		var follows []store.Follow
+		decodeData(t, rr, &follows)
+		names := []string{}
+		for _, f := range follows {
+			names = append(names, f.User.Username)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	t.Run("should list followers, following and mutuals", func(t *testing.T) {
+		for path, want := range map[string][]string{
+			"followers": {"bob"},
+			"following": {"bob", "carol"},
+			"mutuals":   {"bob"},
+		} {
+			got := usernames(t, fmt.Sprintf("/v1/users/%d/%s", alice.ID, path))
+			if !slices.Equal(got, want) {
+				t.Errorf("expected %s %v, got %v", path, want, got)
+			}
+		}
+	})
+
+	t.Run("should paginate the lists", func(t *testing.T) {
+		req := newTestRequest(t, app, http.MethodGet, fmt.Sprintf("/v1/users/%d/following?limit=1", alice.ID), alice, nil)
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr)
+
+		var page struct {
+			Data       []store.Follow `json:"data"`
+			NextCursor string         `json:"next_cursor"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Data) != 1 || page.NextCursor == "" {
+			t.Fatalf("expected one follow and a next cursor, got %+v", page)
+		}
+	})
+
+	t.Run("should include follow counts and the relationship on profiles", func(t *testing.T) {
+		for _, tc := range []struct {
+			user         *store.User
+			stats        store.FollowStats
+			relationship *store.Relationship
+		}{
+			{alice, store.FollowStats{Followers: 1, Following: 2}, nil},
+			{bob, store.FollowStats{Followers: 1, Following: 1}, &store.Relationship{IsFollowing: true, FollowsYou: true}},
+			{carol, store.FollowStats{Followers: 1, Following: 0}, &store.Relationship{IsFollowing: true, FollowsYou: false}},
+		} {
+			req := newTestRequest(t, app, http.MethodGet, fmt.Sprintf("/v1/users/%d", tc.user.ID), alice, nil)
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, http.StatusOK, rr)
+
+			var profile UserProfile
+			decodeData(t, rr, &profile)
+			if profile.FollowStats != tc.stats {
+				t.Errorf("expected %s to have %+v, got %+v", tc.user.Username, tc.stats, profile.FollowStats)
+			}
+			if (profile.Relationship == nil) != (tc.relationship == nil) ||
+				(tc.relationship != nil && *profile.Relationship != *tc.relationship) {
+				t.Errorf("expected %s to have relationship %+v, got %+v", tc.user.Username, tc.relationship, profile.Relationship)
+			}
+		}
+	})
+
+	t.Run("should hide the lists of private and blocking users", func(t *testing.T) {
+		dave := newTestUser(t, app, "dave", "user")
+		erin := newTestUser(t, app, "erin", "user")
+
+		req := newTestRequest(t, app, http.MethodPut, fmt.Sprintf("/v1/users/%d/follow", bob.ID), dave, nil)
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+		if got := usernames(t, fmt.Sprintf("/v1/users/%d/followers", bob.ID)); !slices.Equal(got, []string{"alice", "dave"}) {
+			t.Fatalf("expected bob's followers to be alice and dave, got %v", got)
+		}
+
+		req = newTestRequest(t, app, http.MethodPut, fmt.Sprintf("/v1/users/%d/block", alice.ID), dave, nil)
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+		if got := usernames(t, fmt.Sprintf("/v1/users/%d/followers", bob.ID)); !slices.Equal(got, []string{"alice"}) {
+			t.Fatalf("expected dave to be left out of bob's followers, got %v", got)
+		}
+
+		private := true
+		req = newTestRequest(t, app, http.MethodPut, "/v1/users/me/privacy", erin, UpdatePrivacyPayload{IsPrivate: &private})
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, mux))
+
+		for _, user := range []*store.User{dave, erin} {
+			for _, path := range []string{"followers", "following", "mutuals"} {
+				req := newTestRequest(t, app, http.MethodGet, fmt.Sprintf("/v1/users/%d/%s", user.ID, path), alice, nil)
+				checkResponseCode(t, http.StatusNotFound, executeRequest(req, mux))
+			}
+		}
+
+		req = newTestRequest(t, app, http.MethodGet, fmt.Sprintf("/v1/users/%d/followers", erin.ID), erin, nil)
+		checkResponseCode(t, http.StatusOK, executeRequest(req, mux))
+	})
+}
+
+func TestUpdateUserProfile(t *testing.T) {
+	app, _ := newTestApplication(t)
+	mux := app.mount()
+
+	alice := newTestUser(t, app, "alice", "user")
+	bob := newTestUser(t, app, "bob", "user")
+
+	updateProfile := func(t *testing.T, payload UpdateProfilePayload, code int) store.User {
+		t.Helper()
+
+		req := newTestRequest(t, app, http.MethodPatch, "/v1/users/me", alice, payload)
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, code, rr)
+
+		var user store.User
+		if code == http.StatusOK {
+			decodeData(t, rr, &user)
+		}
+		return user
+	}
+	ptr := func(s string) *string { return &s }
+
+	t.Run("should validate the profile", func(t *testing.T) {
+		updateProfile(t, UpdateProfilePayload{Website: ptr("not a url")}, http.StatusBadRequest)
+		updateProfile(t, UpdateProfilePayload{Username: ptr("")}, http.StatusBadRequest)
+
+		for _, username := range []string{"al ice", "@alice", "alice.", "alice!"} {
+			updateProfile(t, UpdateProfilePayload{Username: ptr(username)}, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("should update the profile details", func(t *testing.T) {
+		user := updateProfile(t, UpdateProfilePayload{
+			DisplayName: ptr("Alice"),
+			Bio:         ptr("Gopher"),
+			Website:     ptr("https://alice.example.com"),
+		}, http.StatusOK)
+		if user.DisplayName != "Alice" || user.Bio != "Gopher" || user.Website != "https://alice.example.com" {
+			t.Fatalf("expected the profile details to be updated, got %+v", user)
+		}
+
+		user = updateProfile(t, UpdateProfilePayload{Website: ptr("")}, http.StatusOK)
+		if user.Website != "" || user.DisplayName != "Alice" {
+			t.Fatalf("expected only the website to be cleared, got %+v", user)
+		}
+	})
+
+	t.Run("should change the username once per cooldown", func(t *testing.T) {
+		updateProfile(t, UpdateProfilePayload{Username: ptr(bob.Username)}, http.StatusConflict)
+
+		user := updateProfile(t, UpdateProfilePayload{Username: ptr("alicia")}, http.StatusOK)
+		if user.Username != "alicia" || user.UsernameChangedAt == nil {
+			t.Fatalf("expected the username to be changed, got %+v", user)
+		}
+
+		updateProfile(t, UpdateProfilePayload{Username: ptr("ally")}, http.StatusBadRequest)
+		updateProfile(t, UpdateProfilePayload{Username: ptr("alicia"), Bio: ptr("Still a gopher")}, http.StatusOK)
+	})
+
+	t.Run("should only show the email to its owner", func(t *testing.T) {
+		url := fmt.Sprintf("/v1/users/%d", alice.ID)
+		for _, viewer := range []*store.User{alice, bob} {
+			req := newTestRequest(t, app, http.MethodGet, url, viewer, nil)
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, http.StatusOK, rr)
+
+			var profile map[string]any
+			decodeData(t, rr, &profile)
+			if _, ok := profile["email"]; ok != (viewer == alice) {
+				t.Fatalf("expected the email to be shown only to alice, %s got %v", viewer.Username, profile)
+			}
+			if profile["display_name"] != "Alice" {
+				t.Fatalf("expected the display name in the profile, got %v", profile)
+			}
+		}
+	})
+}
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS roles (
+    id bigserial PRIMARY KEY,
+    name varchar(255) UNIQUE NOT NULL,
+    level int NOT NULL DEFAULT 0,
+    description text NOT NULL DEFAULT ''
+);
+
+INSERT INTO roles (name, description, level)
+VALUES
+    ('user', 'A user can create posts and comments', 1),
+    ('moderator', 'A moderator can update other users posts and delete their comments', 2),
+    ('admin', 'An admin can update and delete other users posts and comments', 3)
+ON CONFLICT (name) DO NOTHING;
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS password_resets (
+    token bytea primary key,
+    user_id bigint not null,
+    expiry TIMESTAMP(0) WITH TIME ZONE NOT NULL,
+
+    FOREIGN KEY(user_id) REFERENCES users (id) ON DELETE CASCADE
+);
@@ -0,0 +1,7 @@
+ALTER TABLE comments
+ADD COLUMN parent_id bigint REFERENCES comments (id) ON DELETE CASCADE,
+ADD COLUMN depth int NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id, created_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_comments_post_id_top_level ON comments (post_id, created_at, id) WHERE parent_id IS NULL;
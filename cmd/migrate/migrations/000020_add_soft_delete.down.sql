@@ -0,0 +1,17 @@
+-- soft deleted rows would come back to life without the column
+DELETE FROM comments WHERE deleted_at IS NOT NULL;
+
+DELETE FROM posts WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_comments_deleted_at;
+
+DROP INDEX IF EXISTS idx_posts_deleted_at;
+
+ALTER TABLE comments
+DROP CONSTRAINT IF EXISTS fk_comments_post;
+
+ALTER TABLE comments
+DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE posts
+DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,19 @@
+ALTER TABLE posts
+ADD COLUMN deleted_at TIMESTAMP(0) WITH TIME ZONE;
+
+ALTER TABLE comments
+ADD COLUMN deleted_at TIMESTAMP(0) WITH TIME ZONE;
+
+-- comments of posts deleted before soft deletes were left behind. They are
+-- deleted for good rather than moved to the trash: the foreign key below is
+-- checked against every row, trashed or not, and their posts are gone so
+-- they could never be restored. Back them up first if they are needed.
+DELETE FROM comments c
+WHERE NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = c.post_id);
+
+ALTER TABLE comments
+ADD CONSTRAINT fk_comments_post FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_posts_deleted_at ON posts (deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_comments_deleted_at ON comments (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,5 @@
+-- followers of a user, most recent first
+CREATE INDEX IF NOT EXISTS idx_followers_follower_id ON followers (follower_id, created_at, user_id);
+
+-- users a user follows, most recent first
+CREATE INDEX IF NOT EXISTS idx_followers_user_id ON followers (user_id, created_at, follower_id);
@@ -0,0 +1,18 @@
+ALTER TABLE users
+ADD COLUMN is_private boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS follow_requests (
+    requester_id bigint NOT NULL,
+    target_id bigint NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY(requester_id, target_id),
+    FOREIGN KEY(requester_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY(target_id) REFERENCES users (id) ON DELETE CASCADE,
+    CHECK (status IN ('pending', 'approved', 'rejected')),
+    CHECK (requester_id <> target_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_follow_requests_pending ON follow_requests (target_id, created_at, requester_id) WHERE status = 'pending';
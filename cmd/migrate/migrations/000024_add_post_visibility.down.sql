@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_posts_mentions;
+
+ALTER TABLE posts
+DROP CONSTRAINT IF EXISTS chk_posts_visibility,
+DROP COLUMN IF EXISTS mentions,
+DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,6 @@
+ALTER TABLE posts
+ADD COLUMN visibility VARCHAR(10) NOT NULL DEFAULT 'public',
+ADD COLUMN mentions VARCHAR(255)[] NOT NULL DEFAULT '{}',
+ADD CONSTRAINT chk_posts_visibility CHECK (visibility IN ('public', 'followers', 'mentioned'));
+
+CREATE INDEX IF NOT EXISTS idx_posts_mentions ON posts USING gin(mentions);
@@ -0,0 +1,7 @@
+ALTER TABLE users
+DROP COLUMN IF EXISTS username_changed_at,
+DROP COLUMN IF EXISTS avatar_url,
+DROP COLUMN IF EXISTS location,
+DROP COLUMN IF EXISTS website,
+DROP COLUMN IF EXISTS bio,
+DROP COLUMN IF EXISTS display_name;
@@ -0,0 +1,7 @@
+ALTER TABLE users
+ADD COLUMN display_name VARCHAR(100) NOT NULL DEFAULT '',
+ADD COLUMN bio VARCHAR(500) NOT NULL DEFAULT '',
+ADD COLUMN website VARCHAR(255) NOT NULL DEFAULT '',
+ADD COLUMN location VARCHAR(100) NOT NULL DEFAULT '',
+ADD COLUMN avatar_url VARCHAR(255) NOT NULL DEFAULT '',
+ADD COLUMN username_changed_at TIMESTAMP(0) WITH TIME ZONE;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_posts_mentioned_ids;
+CREATE INDEX IF NOT EXISTS idx_posts_mentions ON posts USING gin(mentions);
+
+ALTER TABLE posts
+DROP COLUMN IF EXISTS mentioned_ids;
@@ -0,0 +1,11 @@
+-- mentioned-only posts are shown to the users their mentions named when
+-- they were written, so that renaming doesn't hand them to someone else
+ALTER TABLE posts
+ADD COLUMN mentioned_ids BIGINT[] NOT NULL DEFAULT '{}';
+
+UPDATE posts p
+SET mentioned_ids = ARRAY(SELECT u.id FROM users u WHERE u.username = ANY(p.mentions))
+WHERE p.mentions <> '{}';
+
+DROP INDEX IF EXISTS idx_posts_mentions;
+CREATE INDEX IF NOT EXISTS idx_posts_mentioned_ids ON posts USING gin(mentioned_ids);
@@ -24,6 +24,251 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/authenticate/logout": {
+            "post": {
+                "description": "Revokes the session the refresh token belongs to",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Logs out",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.RefreshTokenPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Logged out"
+                    },
+                    "400": {
+                        "description": "Invalid Logout Payload",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/authenticate/password/forgot": {
+            "post": {
+                "description": "Emails a single-use password reset link if the address belongs to an active user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Request a password reset",
+                "parameters": [
+                    {
+                        "description": "User email",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.ForgotPasswordPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Reset requested"
+                    },
+                    "400": {
+                        "description": "Invalid Payload",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/authenticate/password/reset": {
+            "post": {
+                "description": "Sets a new password using a reset token and signs the user out of every session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Reset a password",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.ResetPasswordPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Password Reset"
+                    },
+                    "400": {
+                        "description": "Invalid Payload",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/authenticate/refresh": {
+            "post": {
+                "description": "Exchanges a refresh token for a new access and refresh token pair",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Refreshes a token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.RefreshTokenPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Tokens",
+                        "schema": {
+                            "$ref": "#/definitions/main.UserTokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Refresh Payload",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or revoked refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/authenticate/token": {
+            "post": {
+                "description": "Creates an access and refresh token pair for an active user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Creates a token",
+                "parameters": [
+                    {
+                        "description": "User credentials",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateUserTokenPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Tokens",
+                        "schema": {
+                            "$ref": "#/definitions/main.UserTokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Token Payload",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Credentials",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
         "/authenticate/user": {
             "post": {
                 "security": [
@@ -57,16 +302,20 @@ const docTemplate = `{
                     "201": {
                         "description": "User Registered",
                         "schema": {
-                            "$ref": "#/definitions/main.UserWithToken"
+                            "$ref": "#/definitions/store.User"
                         }
                     },
                     "400": {
                         "description": "Invalid User Payload",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "500": {
                         "description": "Something went wrong",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     }
                 }
             }
@@ -95,7 +344,15 @@ const docTemplate = `{
                     },
                     "500": {
                         "description": "Something went worng",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "503": {
+                        "description": "API is shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/main.healthResponse"
+                        }
                     }
                 }
             }
@@ -134,15 +391,25 @@ const docTemplate = `{
                         "description": "Post Created",
                         "schema": {
                             "$ref": "#/definitions/store.Post"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Weak ETag of the post version"
+                            }
                         }
                     },
                     "400": {
                         "description": "Invalid Post Payload",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "500": {
                         "description": "Something went wrong",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     }
                 }
             }
@@ -154,7 +421,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetch Post details by ID",
+                "description": "Fetch Post details by ID. Posts hidden from the caller by their visibility, a block or a private author are reported as not found.",
                 "consumes": [
                     "application/json"
                 ],
@@ -172,6 +439,12 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy of the post",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -179,15 +452,34 @@ const docTemplate = `{
                         "description": "Post Details",
                         "schema": {
                             "$ref": "#/definitions/store.Post"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Weak ETag of the post with its comments and reactions"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Post Not Modified"
+                    },
+                    "400": {
+                        "description": "Invalid post ID",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
                         }
                     },
                     "404": {
                         "description": "Post Not found",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "500": {
                         "description": "Something went wrong",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     }
                 }
             },
@@ -197,7 +489,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete Post details by ID",
+                "description": "Move a post to the trash, from where it can be restored until it is purged",
                 "consumes": [
                     "application/json"
                 ],
@@ -215,19 +507,53 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the post must still have",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "204": {
                         "description": "Post Deleted"
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
                     "404": {
                         "description": "Post not found",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Post modified concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Post has been modified",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header required",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "500": {
                         "description": "Something went wrong",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     }
                 }
             },
@@ -256,6 +582,12 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "ETag the post must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
                     {
                         "description": "Updated Post details",
                         "name": "post",
@@ -271,19 +603,55 @@ const docTemplate = `{
                         "description": "Post Updated",
                         "schema": {
                             "$ref": "#/definitions/store.Post"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Weak ETag of the new post version"
+                            }
                         }
                     },
                     "400": {
                         "description": "Invalid Post Payload",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "404": {
                         "description": "Post not found",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Post modified concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Post has been modified",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header required",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "500": {
                         "description": "Something went wrong",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     }
                 }
             }
@@ -333,27 +701,39 @@ const docTemplate = `{
                     },
                     "400": {
                         "description": "Invalid Comment Payload",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
-                    "404": {
-                        "description": "Post not found",
-                        "schema": {}
+                    "403": {
+                        "description": "Blocked by the post author",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Post not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "500": {
                         "description": "Something Went wrong",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     }
                 }
             }
         },
-        "/posts/{postID}/comments/{commentID}": {
-            "delete": {
+        "/posts/{postID}/comments": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete Comment details by ID",
+                "description": "Fetch a page of a post's top-level comments with nested replies",
                 "consumes": [
                     "application/json"
                 ],
@@ -363,7 +743,7 @@ const docTemplate = `{
                 "tags": [
                     "comments"
                 ],
-                "summary": "Delete Comment",
+                "summary": "Fetch Comments",
                 "parameters": [
                     {
                         "type": "integer",
@@ -374,35 +754,80 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Comment ID",
-                        "name": "commentID",
-                        "in": "path",
-                        "required": true
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_cursor or prev_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "tree (default) or flat",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Levels of replies to include",
+                        "name": "depth",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Replies to include per comment",
+                        "name": "replies",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Comment Deleted"
+                    "200": {
+                        "description": "Comments",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Comment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Comment Query",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "404": {
-                        "description": "Comment Not found",
-                        "schema": {}
+                        "description": "Post not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "500": {
                         "description": "Something went wrong",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     }
                 }
             }
         },
-        "/users/activate/{token}": {
-            "put": {
+        "/posts/{postID}/comments/{commentID}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Activate User by token",
+                "description": "Move a comment to the trash, from where it can be restored until it is purged",
                 "consumes": [
                     "application/json"
                 ],
@@ -410,41 +835,58 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "comments"
                 ],
-                "summary": "Activate User",
+                "summary": "Delete Comment",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "token",
-                        "name": "token",
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Comment ID",
+                        "name": "commentID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "204": {
-                        "description": "User Activated"
+                        "description": "Comment Deleted"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "404": {
-                        "description": "User not found",
-                        "schema": {}
+                        "description": "Comment Not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "500": {
                         "description": "Something went wrong",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     }
                 }
             }
         },
-        "/users/feed": {
+        "/posts/{postID}/comments/{commentID}/replies": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetch User Feed by ID",
+                "description": "Fetch a page of the replies to a comment with their own nested replies",
                 "consumes": [
                     "application/json"
                 ],
@@ -452,20 +894,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "comments"
                 ],
-                "summary": "Fetch User Feed",
+                "summary": "Fetch Replies",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Limit",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Offset",
-                        "name": "offset",
+                        "description": "Comment ID",
+                        "name": "commentID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
                         "in": "query"
                     },
                     {
@@ -476,44 +926,66 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Tags",
-                        "name": "tags",
+                        "description": "Cursor from next_cursor or prev_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "tree (default) or flat",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Levels of replies to include",
+                        "name": "depth",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Replies to include per comment",
+                        "name": "replies",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User Feed",
+                        "description": "Replies",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/store.PostWithMetadata"
+                                "$ref": "#/definitions/store.Comment"
                             }
                         }
                     },
                     "400": {
-                        "description": "Invalid Feed payload",
-                        "schema": {}
+                        "description": "Invalid Comment Query",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "404": {
-                        "description": "Feed not found",
-                        "schema": {}
+                        "description": "Comment not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "500": {
                         "description": "Something went wrong",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     }
                 }
-            }
-        },
-        "/users/{id}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetches a user profile by ID",
+                "description": "Creates a reply to a comment and returns its details",
                 "consumes": [
                     "application/json"
                 ],
@@ -521,44 +993,76 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "comments"
                 ],
-                "summary": "Fetches a user profile",
+                "summary": "Reply to a Comment",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Comment ID",
+                        "name": "commentID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Reply",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateCommentPayload"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "User Details",
+                    "201": {
+                        "description": "Reply Created",
                         "schema": {
-                            "$ref": "#/definitions/store.User"
+                            "$ref": "#/definitions/store.Comment"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Reply Payload or thread too deep",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Blocked by the comment author",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
                         }
                     },
                     "404": {
-                        "description": "User not found",
-                        "schema": {}
+                        "description": "Comment not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "500": {
-                        "description": "Something went wrong",
-                        "schema": {}
+                        "description": "Something Went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     }
                 }
             }
         },
-        "/users/{id}/follow": {
-            "put": {
+        "/posts/{postID}/comments/{commentID}/restore": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Follow a user by ID",
+                "description": "Take a deleted comment, and the replies hidden with it, out of the trash within the retention period",
                 "consumes": [
                     "application/json"
                 ],
@@ -566,54 +1070,61 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "comments"
                 ],
-                "summary": "Follow a user",
+                "summary": "Restore Comment",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
+                        "description": "Post ID",
+                        "name": "postID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.FollowRequest"
-                        }
+                        "type": "integer",
+                        "description": "Comment ID",
+                        "name": "commentID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Followed User"
+                    "200": {
+                        "description": "Comment Restored",
+                        "schema": {
+                            "$ref": "#/definitions/store.Comment"
+                        }
                     },
-                    "400": {
-                        "description": "Invalid Follow Payload",
-                        "schema": {}
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "404": {
-                        "description": "User not found",
-                        "schema": {}
+                        "description": "Comment not found in the trash",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "500": {
                         "description": "Something went wrong",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     }
                 }
             }
         },
-        "/users/{id}/unfollow": {
-            "put": {
+        "/posts/{postID}/reactions/{kind}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Unfollow a user by ID",
+                "description": "Fetch a page of the users who reacted to a post with the given kind",
                 "consumes": [
                     "application/json"
                 ],
@@ -621,42 +1132,1713 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "reactions"
                 ],
-                "summary": "Unfollow a user",
+                "summary": "Fetch Reactions",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
+                        "description": "Post ID",
+                        "name": "postID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.FollowRequest"
-                        }
+                        "type": "string",
+                        "description": "like, love, haha, wow, sad or angry",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_cursor or prev_cursor",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Unfollowed User"
-                    },
-                    "400": {
+                    "200": {
+                        "description": "Reactions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Reaction"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reaction query",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Post not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds the authenticated user's reaction of the given kind to a post",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reactions"
+                ],
+                "summary": "React to a Post",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "like, love, haha, wow, sad or angry",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reaction",
+                        "schema": {
+                            "$ref": "#/definitions/store.Reaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reaction kind",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Post not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes the authenticated user's reaction of the given kind from a post",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reactions"
+                ],
+                "summary": "Remove a Reaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "like, love, haha, wow, sad or angry",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Reaction Removed"
+                    },
+                    "400": {
+                        "description": "Invalid reaction kind",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Reaction not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{postID}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Take a deleted post out of the trash, within the retention period",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Restore Post",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Post Restored",
+                        "schema": {
+                            "$ref": "#/definitions/store.Post"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Weak ETag of the post version"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Post not found in the trash",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{postID}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetch a page of the previous versions of a post, most recent first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Fetch Post Revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_cursor or prev_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Revisions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.PostRevision"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid revision query",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Post not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{postID}/revisions/diff": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Compare two versions of a post as unified diffs of its title, content and tags",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Diff Post Revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to compare from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to compare to, the current one by default",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Diff",
+                        "schema": {
+                            "$ref": "#/definitions/main.RevisionDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid versions",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Revision not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{postID}/revisions/{version}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetch a post as it was at the given version, which may be the current one",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Fetch Post Revision",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Post version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Revision",
+                        "schema": {
+                            "$ref": "#/definitions/store.PostRevision"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid version",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Revision not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{postID}/revisions/{version}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Restore the title, content and tags of an older version as a new version of the post",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Restore Post Revision",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to restore",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the post must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Post Restored",
+                        "schema": {
+                            "$ref": "#/definitions/store.Post"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Weak ETag of the new post version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid version",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Revision not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Post modified concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Post has been modified",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header required",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Full-text search over posts, users or comments, ranked by relevance",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "search"
+                ],
+                "summary": "Search",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search terms, websearch syntax (quotes, OR, -)",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "posts (default), users or comments",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_cursor or prev_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Search results",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.PostSearchResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid search query",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/activate/{token}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Activate User by token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Activate User",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "User Activated"
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/feed": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetch User Feed by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Fetch User Feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_cursor or prev_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tags",
+                        "name": "tags",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User Feed",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.PostWithMetadata"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Feed payload",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Feed not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update the authenticated user's username and profile details. Fields left out are kept, and the username can only be changed once per cooldown.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update your profile",
+                "parameters": [
+                    {
+                        "description": "Profile details",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateProfilePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile Updated",
+                        "schema": {
+                            "$ref": "#/definitions/store.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid payload or username changed too recently",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Username taken",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/follow-requests": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetch a page of the pending requests to follow the authenticated user, most recent first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List follow requests",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_cursor or prev_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pending Follow Requests",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.FollowRequest"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid follow query",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/follow-requests/{userID}/approve": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Approve a user's pending request to follow the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Approve a follow request",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Requester ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Follow Request Approved"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Follow request not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/follow-requests/{userID}/reject": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Reject a user's pending request to follow the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Reject a follow request",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Requester ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Follow Request Rejected"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Follow request not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/privacy": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Make the authenticated user's account private, so that follows need approval, or public, which approves the pending follow requests",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update account privacy",
+                "parameters": [
+                    {
+                        "description": "Privacy",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdatePrivacyPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Privacy Updated"
+                    },
+                    "400": {
+                        "description": "Invalid Privacy Payload",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the active sessions of the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List sessions",
+                "responses": {
+                    "200": {
+                        "description": "Active Sessions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Session"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/sessions/{sessionID}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revokes one of the authenticated user's sessions by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Revoke a session",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "sessionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Session Revoked"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches a user profile by ID, with follow counts and the relationship to the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Fetches a user profile",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User Details",
+                        "schema": {
+                            "$ref": "#/definitions/main.UserProfile"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/follow": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Follow a user by ID. Following a private account sends it a follow request to approve instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Follow a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Follow Request Sent",
+                        "schema": {
+                            "$ref": "#/definitions/store.FollowRequest"
+                        }
+                    },
+                    "204": {
+                        "description": "Followed User"
+                    },
+                    "400": {
+                        "description": "Invalid Follow Payload",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Blocked",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Already following",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/unfollow": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Unfollow a user by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Unfollow a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Unfollowed User"
+                    },
+                    "400": {
                         "description": "Invalid Unfollow Payload",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/block": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Block a user, removing the follows between the two users and hiding their posts from each other",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Block a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "User Blocked"
+                    },
+                    "400": {
+                        "description": "Cannot block yourself",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Unblock a user, without restoring the follows removed by the block",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Unblock a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "User Unblocked"
+                    },
+                    "404": {
+                        "description": "User not found or not blocked",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/followers": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetch a page of the users following a user, most recent first. The lists of private accounts the caller doesn't follow, and of users blocking or blocked by the caller, are reported as not found.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Fetch Followers",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_cursor or prev_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Followers",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Follow"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid follow query",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/following": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetch a page of the users a user follows, most recent first. The lists of private accounts the caller doesn't follow, and of users blocking or blocked by the caller, are reported as not found.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Fetch Following",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_cursor or prev_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Followed users",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Follow"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid follow query",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/mute": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Mute a user, leaving their posts out of your feed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Mute a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "User Muted"
+                    },
+                    "400": {
+                        "description": "Cannot mute yourself",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Unmute a user, bringing their posts back to your feed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Unmute a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "User Unmuted"
+                    },
+                    "404": {
+                        "description": "User not found or not muted",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/mutuals": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetch a page of the users a user follows who follow them back, most recent first. The lists of private accounts the caller doesn't follow, and of users blocking or blocked by the caller, are reported as not found.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Fetch Mutual Follows",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_cursor or prev_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Mutual follows",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Follow"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid follow query",
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "404": {
                         "description": "User not found",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     },
                     "500": {
                         "description": "Something went wrong",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/main.problem"
+                        }
                     }
                 }
             }
@@ -666,17 +2848,13 @@ const docTemplate = `{
         "main.CreateCommentPayload": {
             "type": "object",
             "required": [
-                "content",
-                "user_id"
+                "content"
             ],
             "properties": {
                 "content": {
                     "type": "string",
                     "maxLength": 100,
                     "minLength": 6
-                },
-                "user_id": {
-                    "type": "integer"
                 }
             }
         },
@@ -700,14 +2878,57 @@ const docTemplate = `{
                 "title": {
                     "type": "string",
                     "maxLength": 100
+                },
+                "visibility": {
+                    "description": "Visibility defaults to public",
+                    "type": "string",
+                    "enum": [
+                        "public",
+                        "followers",
+                        "mentioned"
+                    ]
                 }
             }
         },
-        "main.FollowRequest": {
+        "main.CreateUserTokenPayload": {
             "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
             "properties": {
-                "user_id": {
-                    "type": "integer"
+                "email": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 3
+                }
+            }
+        },
+        "main.ForgotPasswordPayload": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "main.RefreshTokenPayload": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string",
+                    "maxLength": 255
                 }
             }
         },
@@ -723,97 +2944,418 @@ const docTemplate = `{
                     "type": "string",
                     "maxLength": 255
                 },
-                "password": {
-                    "type": "string",
-                    "maxLength": 72,
-                    "minLength": 3
+                "password": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 3
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "main.ResetPasswordPayload": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 3
+                },
+                "token": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "main.RevisionDiff": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.UpdatePostPayload": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "visibility": {
+                    "type": "string",
+                    "enum": [
+                        "public",
+                        "followers",
+                        "mentioned"
+                    ]
+                }
+            }
+        },
+        "main.UpdatePrivacyPayload": {
+            "type": "object",
+            "required": [
+                "is_private"
+            ],
+            "properties": {
+                "is_private": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "main.UpdateProfilePayload": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "bio": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "display_name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "location": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                },
+                "website": {
+                    "description": "Website and AvatarURL are http(s) URLs, or empty to clear them",
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "main.UserProfile": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "bio": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "description": "profile details, empty until the user fills them in",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "followers_count": {
+                    "type": "integer"
+                },
+                "following_count": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "is_private": {
+                    "description": "IsPrivate accounts approve their followers and only show their posts\nto them.",
+                    "type": "boolean"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "relationship": {
+                    "$ref": "#/definitions/store.Relationship"
+                },
+                "role": {
+                    "$ref": "#/definitions/store.Role"
+                },
+                "role_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                },
+                "username_changed_at": {
+                    "description": "UsernameChangedAt is when the username was last changed, if ever.",
+                    "type": "string"
+                },
+                "website": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.UserTokens": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.fieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.healthResponse": {
+            "type": "object",
+            "properties": {
+                "env": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
                 },
-                "username": {
-                    "type": "string",
-                    "maxLength": 100
+                "version": {
+                    "type": "string"
                 }
             }
         },
-        "main.UpdatePostPayload": {
+        "main.problem": {
             "type": "object",
             "properties": {
-                "content": {
-                    "type": "string",
-                    "maxLength": 1000
+                "detail": {
+                    "type": "string"
                 },
-                "tags": {
+                "errors": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/main.fieldError"
                     }
                 },
+                "instance": {
+                    "description": "Instance is the ID of the request the problem occurred in",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
                 "title": {
-                    "type": "string",
-                    "maxLength": 100
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
                 }
             }
         },
-        "main.UserWithToken": {
+        "store.Comment": {
             "type": "object",
             "properties": {
+                "content": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
-                "email": {
+                "deleted_at": {
+                    "description": "DeletedAt is set while the comment is in the trash.",
                     "type": "string"
                 },
+                "depth": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "is_active": {
-                    "type": "boolean"
+                "parent_id": {
+                    "type": "integer"
                 },
-                "token": {
-                    "type": "string"
+                "path": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 },
-                "username": {
+                "post_id": {
+                    "type": "integer"
+                },
+                "replies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Comment"
+                    }
+                },
+                "reply_count": {
+                    "type": "integer"
+                },
+                "user": {
+                    "$ref": "#/definitions/store.User"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.Follow": {
+            "type": "object",
+            "properties": {
+                "followed_at": {
                     "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/store.User"
                 }
             }
         },
-        "main.healthResponse": {
+        "store.FollowRequest": {
             "type": "object",
             "properties": {
-                "env": {
+                "created_at": {
                     "type": "string"
                 },
+                "requester": {
+                    "$ref": "#/definitions/store.User"
+                },
+                "requester_id": {
+                    "type": "integer"
+                },
                 "status": {
                     "type": "string"
                 },
-                "version": {
+                "target_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "store.Comment": {
+        "store.Post": {
             "type": "object",
             "properties": {
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Comment"
+                    }
+                },
                 "content": {
                     "type": "string"
                 },
                 "created_at": {
                     "type": "string"
                 },
+                "deleted_at": {
+                    "description": "DeletedAt is set while the post is in the trash.",
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "post_id": {
-                    "type": "integer"
+                "mentions": {
+                    "description": "Mentions are the usernames @mentioned in the title and content.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "reactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ReactionCount"
+                    }
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 },
                 "user": {
                     "$ref": "#/definitions/store.User"
                 },
                 "user_id": {
                     "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                },
+                "visibility": {
+                    "description": "Visibility is one of PostPublic, PostFollowers or PostMentioned.",
+                    "type": "string"
                 }
             }
         },
-        "store.Post": {
+        "store.PostRevision": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "CreatedAt is when the post was saved at Version.",
+                    "type": "string"
+                },
+                "post_id": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.PostSearchResult": {
             "type": "object",
             "properties": {
                 "comments": {
@@ -828,9 +3370,32 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "deleted_at": {
+                    "description": "DeletedAt is set while the post is in the trash.",
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "mentions": {
+                    "description": "Mentions are the usernames @mentioned in the title and content.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "reactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ReactionCount"
+                    }
+                },
+                "snippet": {
+                    "type": "string"
+                },
                 "tags": {
                     "type": "array",
                     "items": {
@@ -851,6 +3416,10 @@ const docTemplate = `{
                 },
                 "version": {
                     "type": "integer"
+                },
+                "visibility": {
+                    "description": "Visibility is one of PostPublic, PostFollowers or PostMentioned.",
+                    "type": "string"
                 }
             }
         },
@@ -872,9 +3441,26 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "deleted_at": {
+                    "description": "DeletedAt is set while the post is in the trash.",
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "mentions": {
+                    "description": "Mentions are the usernames @mentioned in the title and content.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "reactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ReactionCount"
+                    }
+                },
                 "tags": {
                     "type": "array",
                     "items": {
@@ -895,15 +3481,114 @@ const docTemplate = `{
                 },
                 "version": {
                     "type": "integer"
+                },
+                "visibility": {
+                    "description": "Visibility is one of PostPublic, PostFollowers or PostMentioned.",
+                    "type": "string"
+                }
+            }
+        },
+        "store.Reaction": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "post_id": {
+                    "type": "integer"
+                },
+                "user": {
+                    "$ref": "#/definitions/store.User"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.ReactionCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "reacted_by_me": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "store.Relationship": {
+            "type": "object",
+            "properties": {
+                "follows_you": {
+                    "type": "boolean"
+                },
+                "is_following": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "store.Role": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "level": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.Session": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expiry": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
         "store.User": {
             "type": "object",
             "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "bio": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "display_name": {
+                    "description": "profile details, empty until the user fills them in",
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
@@ -913,8 +3598,28 @@ const docTemplate = `{
                 "is_active": {
                     "type": "boolean"
                 },
+                "is_private": {
+                    "description": "IsPrivate accounts approve their followers and only show their posts\nto them.",
+                    "type": "boolean"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/store.Role"
+                },
+                "role_id": {
+                    "type": "integer"
+                },
                 "username": {
                     "type": "string"
+                },
+                "username_changed_at": {
+                    "description": "UsernameChangedAt is when the username was last changed, if ever.",
+                    "type": "string"
+                },
+                "website": {
+                    "type": "string"
                 }
             }
         }
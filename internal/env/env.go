@@ -3,6 +3,7 @@ package env
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 func GetString(key, fallback string) string {
@@ -24,3 +25,27 @@ func GetInt(key string, fallback int) int {
 	}
 	return valAsInt
 }
+
+func GetDuration(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	valAsDuration, err := time.ParseDuration(value)
+	if err != nil {
+		return fallback
+	}
+	return valAsDuration
+}
+
+func GetBool(key string, fallback bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	valAsBool, err := strconv.ParseBool(value)
+	if err != nil {
+		return fallback
+	}
+	return valAsBool
+}
@@ -0,0 +1,86 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"text/template"
+	"time"
+)
+
+const (
+	FromName              = "GopherSocial"
+	maxRetries            = 3
+	UserWelcomeTemplate   = "user_invitation.tmpl"
+	PasswordResetTemplate = "password_reset.tmpl"
+)
+
+// baseRetryDelay is doubled after every failed attempt.
+var baseRetryDelay = time.Second
+
+// sleep waits out the delay between attempts; tests replace it to skip the
+// wait.
+var sleep = time.Sleep
+
+//go:embed "templates"
+var FS embed.FS
+
+type Client interface {
+	Send(templateFile, username, email string, data any) error
+}
+
+type message struct {
+	subject   string
+	plainBody string
+	htmlBody  string
+}
+
+// render executes the subject, plainBody and htmlBody blocks of templateFile.
+func render(templateFile string, data any) (*message, error) {
+	tmpl, err := template.ParseFS(FS, "templates/"+templateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(subject, "subject", data); err != nil {
+		return nil, err
+	}
+
+	plainBody := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(plainBody, "plainBody", data); err != nil {
+		return nil, err
+	}
+
+	htmlTmpl, err := htmltemplate.ParseFS(FS, "templates/"+templateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	htmlBody := new(bytes.Buffer)
+	if err := htmlTmpl.ExecuteTemplate(htmlBody, "htmlBody", data); err != nil {
+		return nil, err
+	}
+
+	return &message{
+		subject:   subject.String(),
+		plainBody: plainBody.String(),
+		htmlBody:  htmlBody.String(),
+	}, nil
+}
+
+func withRetry(fn func() error) error {
+	var err error
+	delay := baseRetryDelay
+	for i := 0; i < maxRetries; i++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if i < maxRetries-1 {
+			sleep(delay)
+			delay *= 2
+		}
+	}
+	return fmt.Errorf("failed to send email after %d attempts, error: %w", maxRetries, err)
+}
@@ -0,0 +1,75 @@
+package mailer
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// stubSleep records the delays withRetry waits for instead of sleeping.
+func stubSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+
+	var delays []time.Duration
+	sleep = func(d time.Duration) { delays = append(delays, d) }
+	t.Cleanup(func() { sleep = time.Sleep })
+
+	return &delays
+}
+
+func TestWithRetry(t *testing.T) {
+	errSend := errors.New("connection refused")
+
+	t.Run("should back off until an attempt succeeds", func(t *testing.T) {
+		delays := stubSleep(t)
+
+		attempts := 0
+		err := withRetry(func() error {
+			attempts++
+			if attempts < 3 {
+				return errSend
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if attempts != 3 {
+			t.Fatalf("expected 3 attempts, got %d", attempts)
+		}
+		if want := []time.Duration{time.Second, 2 * time.Second}; !slices.Equal(*delays, want) {
+			t.Fatalf("expected delays %v, got %v", want, *delays)
+		}
+	})
+
+	t.Run("should give up after the last attempt", func(t *testing.T) {
+		delays := stubSleep(t)
+
+		attempts := 0
+		err := withRetry(func() error {
+			attempts++
+			return errSend
+		})
+		if !errors.Is(err, errSend) {
+			t.Fatalf("expected the last error to be wrapped, got %v", err)
+		}
+		if attempts != maxRetries {
+			t.Fatalf("expected %d attempts, got %d", maxRetries, attempts)
+		}
+		if len(*delays) != maxRetries-1 {
+			t.Fatalf("expected no delay after the last attempt, got %v", *delays)
+		}
+	})
+
+	t.Run("should not wait when the first attempt succeeds", func(t *testing.T) {
+		delays := stubSleep(t)
+
+		if err := withRetry(func() error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+		if len(*delays) != 0 {
+			t.Fatalf("expected no delay, got %v", *delays)
+		}
+	})
+}
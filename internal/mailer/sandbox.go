@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// SandboxMailer writes rendered emails to w instead of delivering them, so
+// development setups can follow links without an SMTP server.
+type SandboxMailer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewSandbox(w io.Writer) *SandboxMailer {
+	return &SandboxMailer{w: w}
+}
+
+func (m *SandboxMailer) Send(templateFile, username, email string, data any) error {
+	msg, err := render(templateFile, data)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err = fmt.Fprintf(m.w,
+		"To: %s <%s>\nSubject: %s\n\n%s\n\n%s\n-----\n",
+		username, email, msg.subject, msg.plainBody, msg.htmlBody,
+	)
+	return err
+}
@@ -0,0 +1,97 @@
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+)
+
+type SMTPMailer struct {
+	addr      string
+	host      string
+	auth      smtp.Auth
+	fromEmail string
+}
+
+func NewSMTP(host string, port int, username, password, fromEmail string) (*SMTPMailer, error) {
+	if host == "" {
+		return nil, errors.New("smtp host is required")
+	}
+
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		addr:      net.JoinHostPort(host, strconv.Itoa(port)),
+		host:      host,
+		auth:      auth,
+		fromEmail: fromEmail,
+	}, nil
+}
+
+func (m *SMTPMailer) Send(templateFile, username, email string, data any) error {
+	msg, err := render(templateFile, data)
+	if err != nil {
+		return err
+	}
+
+	from := mail.Address{Name: FromName, Address: m.fromEmail}
+	to := mail.Address{Name: username, Address: email}
+
+	body, err := buildMIME(from, to, msg)
+	if err != nil {
+		return err
+	}
+
+	return withRetry(func() error {
+		return smtp.SendMail(m.addr, m.auth, m.fromEmail, []string{email}, body)
+	})
+}
+
+// buildMIME encodes msg as a multipart/alternative email with a plain text
+// part followed by the HTML part.
+func buildMIME(from, to mail.Address, msg *message) ([]byte, error) {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", msg.plainBody},
+		{"text/html; charset=UTF-8", msg.htmlBody},
+	}
+	for _, p := range parts {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(p.content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	header := new(bytes.Buffer)
+	fmt.Fprintf(header, "From: %s\r\n", from.String())
+	fmt.Fprintf(header, "To: %s\r\n", to.String())
+	fmt.Fprintf(header, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", msg.subject))
+	fmt.Fprintf(header, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(header, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", mw.Boundary())
+
+	return append(header.Bytes(), body.Bytes()...), nil
+}
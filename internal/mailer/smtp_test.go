@@ -0,0 +1,254 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestBuildMIME(t *testing.T) {
+	from := mail.Address{Name: FromName, Address: "no-reply@example.com"}
+	to := mail.Address{Name: "gopher", Address: "gopher@example.com"}
+	msg := &message{
+		subject:   "Héllo gopher",
+		plainBody: "Hi gopher",
+		htmlBody:  "<p>Hi gopher</p>",
+	}
+
+	raw, err := buildMIME(from, to, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := m.Header.Get("From"); got != from.String() {
+		t.Fatalf("expected From %q, got %q", from.String(), got)
+	}
+	if got := m.Header.Get("To"); got != to.String() {
+		t.Fatalf("expected To %q, got %q", to.String(), got)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != msg.subject {
+		t.Fatalf("expected Subject %q, got %q", msg.subject, subject)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("expected multipart/alternative, got %s", mediaType)
+	}
+
+	want := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", msg.plainBody},
+		{"text/html; charset=UTF-8", msg.htmlBody},
+	}
+	mr := multipart.NewReader(m.Body, params["boundary"])
+	for _, w := range want {
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := part.Header.Get("Content-Type"); got != w.contentType {
+			t.Fatalf("expected part of type %q, got %q", w.contentType, got)
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != w.content {
+			t.Fatalf("expected part content %q, got %q", w.content, content)
+		}
+	}
+	if _, err := mr.NextPart(); err != io.EOF {
+		t.Fatalf("expected only two parts, got %v", err)
+	}
+}
+
+// smtpServer is a fake SMTP server accepting every message sent to it, after
+// turning away the first reject connections.
+type smtpServer struct {
+	ln     net.Listener
+	reject int
+
+	mu       sync.Mutex
+	attempts int
+	messages []string
+}
+
+func newSMTPServer(t *testing.T, reject int) *smtpServer {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &smtpServer{ln: ln, reject: reject}
+	go s.serve()
+
+	return s
+}
+
+func (s *smtpServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.handle(conn)
+	}
+}
+
+func (s *smtpServer) handle(conn net.Conn) {
+	defer conn.Close()
+	tc := textproto.NewConn(conn)
+
+	s.mu.Lock()
+	s.attempts++
+	rejected := s.attempts <= s.reject
+	s.mu.Unlock()
+
+	if rejected {
+		tc.PrintfLine("421 service not available")
+		return
+	}
+	tc.PrintfLine("220 localhost ESMTP")
+
+	for {
+		line, err := tc.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, _, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO", "MAIL", "RCPT", "RSET", "NOOP":
+			tc.PrintfLine("250 OK")
+		case "DATA":
+			tc.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+			data, err := tc.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, string(data))
+			s.mu.Unlock()
+			tc.PrintfLine("250 OK")
+		case "QUIT":
+			tc.PrintfLine("221 bye")
+			return
+		default:
+			tc.PrintfLine("502 command not implemented")
+		}
+	}
+}
+
+// received returns the number of connections made and the messages sent.
+func (s *smtpServer) received() (int, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.attempts, slices.Clone(s.messages)
+}
+
+func (s *smtpServer) port(t *testing.T) int {
+	t.Helper()
+
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	data := struct {
+		Username string
+		ResetURL string
+		Expiry   string
+	}{"gopher", "http://localhost/password/reset/token", "1h0m0s"}
+
+	send := func(t *testing.T, server *smtpServer) error {
+		t.Helper()
+
+		m, err := NewSMTP("127.0.0.1", server.port(t), "", "", "no-reply@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m.Send(PasswordResetTemplate, "gopher", "gopher@example.com", data)
+	}
+
+	t.Run("should deliver the rendered email", func(t *testing.T) {
+		server := newSMTPServer(t, 0)
+
+		if err := send(t, server); err != nil {
+			t.Fatal(err)
+		}
+
+		_, messages := server.received()
+		if len(messages) != 1 {
+			t.Fatalf("expected 1 message, got %d", len(messages))
+		}
+		m, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(messages[0])))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := m.Header.Get("To"); got != `"gopher" <gopher@example.com>` {
+			t.Fatalf("expected the email to be addressed to gopher, got %q", got)
+		}
+		body, err := io.ReadAll(m.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(body), data.ResetURL) {
+			t.Fatalf("expected the body to contain the reset URL, got %s", body)
+		}
+	})
+
+	t.Run("should retry when the server turns the connection away", func(t *testing.T) {
+		delays := stubSleep(t)
+		server := newSMTPServer(t, 1)
+
+		if err := send(t, server); err != nil {
+			t.Fatal(err)
+		}
+
+		attempts, messages := server.received()
+		if attempts != 2 || len(messages) != 1 {
+			t.Fatalf("expected 1 message in 2 attempts, got %d in %d", len(messages), attempts)
+		}
+		if len(*delays) != 1 {
+			t.Fatalf("expected 1 delay, got %v", *delays)
+		}
+	})
+
+	t.Run("should fail once every attempt is turned away", func(t *testing.T) {
+		stubSleep(t)
+		server := newSMTPServer(t, maxRetries)
+
+		if err := send(t, server); err == nil {
+			t.Fatal("expected the send to fail")
+		}
+		if _, messages := server.received(); len(messages) != 0 {
+			t.Fatalf("expected no message, got %d", len(messages))
+		}
+	})
+}
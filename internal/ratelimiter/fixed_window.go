@@ -0,0 +1,77 @@
+package ratelimiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type fixedWindow struct {
+	start time.Time
+	count int
+}
+
+// FixedWindowRateLimiter allows up to limit requests per key in consecutive,
+// non-overlapping windows of the given length.
+type FixedWindowRateLimiter struct {
+	sync.Mutex
+	clients   map[string]*fixedWindow
+	limit     int
+	window    time.Duration
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewFixedWindowLimiter(limit int, window time.Duration) *FixedWindowRateLimiter {
+	return &FixedWindowRateLimiter{
+		clients:   make(map[string]*fixedWindow),
+		limit:     limit,
+		window:    window,
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (rl *FixedWindowRateLimiter) Allow(_ context.Context, key string) (Result, error) {
+	rl.Lock()
+	defer rl.Unlock()
+
+	now := rl.now()
+	rl.sweep(now)
+
+	w, ok := rl.clients[key]
+	if !ok || now.Sub(w.start) >= rl.window {
+		w = &fixedWindow{start: now}
+		rl.clients[key] = w
+	}
+
+	reset := w.start.Add(rl.window).Sub(now)
+	res := Result{
+		Limit: rl.limit,
+		Reset: reset,
+	}
+
+	if w.count >= rl.limit {
+		res.RetryAfter = reset
+		return res, nil
+	}
+
+	w.count++
+	res.Allowed = true
+	res.Remaining = rl.limit - w.count
+	return res, nil
+}
+
+// sweep drops expired windows at most once per window so idle keys don't
+// accumulate.
+func (rl *FixedWindowRateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < rl.window {
+		return
+	}
+	for key, w := range rl.clients {
+		if now.Sub(w.start) >= rl.window {
+			delete(rl.clients, key)
+		}
+	}
+	rl.lastSweep = now
+}
@@ -0,0 +1,82 @@
+package ratelimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced time source for the limiters' now field.
+// It starts at the current time, which the limiters' last sweep is set to.
+type fakeClock struct {
+	t time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Now()}
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// allow calls Allow on rl and fails t on error.
+func allow(t *testing.T, rl Limiter, key string) Result {
+	t.Helper()
+
+	res, err := rl.Allow(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestFixedWindowLimiter(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewFixedWindowLimiter(2, time.Minute)
+	rl.now = clock.now
+
+	t.Run("should allow up to the limit within a window", func(t *testing.T) {
+		if res := allow(t, rl, "a"); !res.Allowed || res.Remaining != 1 || res.Reset != time.Minute {
+			t.Fatalf("expected the first request allowed with 1 left, got %+v", res)
+		}
+
+		clock.advance(20 * time.Second)
+		if res := allow(t, rl, "a"); !res.Allowed || res.Remaining != 0 {
+			t.Fatalf("expected the second request allowed with none left, got %+v", res)
+		}
+
+		clock.advance(10 * time.Second)
+		res := allow(t, rl, "a")
+		if res.Allowed {
+			t.Fatal("expected the third request to be limited")
+		}
+		if res.RetryAfter != 30*time.Second || res.Reset != 30*time.Second {
+			t.Fatalf("expected to retry when the window ends in 30s, got %+v", res)
+		}
+	})
+
+	t.Run("should count keys separately", func(t *testing.T) {
+		if res := allow(t, rl, "b"); !res.Allowed {
+			t.Fatal("expected another key to be allowed")
+		}
+	})
+
+	t.Run("should start a new window once the last one ends", func(t *testing.T) {
+		clock.advance(30 * time.Second)
+
+		res := allow(t, rl, "a")
+		if !res.Allowed || res.Remaining != 1 || res.Reset != time.Minute {
+			t.Fatalf("expected a fresh window, got %+v", res)
+		}
+	})
+
+	t.Run("should sweep expired windows", func(t *testing.T) {
+		clock.advance(2 * time.Minute)
+		allow(t, rl, "c")
+
+		if _, ok := rl.clients["b"]; ok {
+			t.Fatal("expected the idle key to be swept")
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package ratelimiter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type Limiter interface {
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+type Config struct {
+	RequestsPerTimeFrame int
+	TimeFrame            time.Duration
+	Enabled              bool
+}
+
+// Result describes the state of a key's quota after a call to Allow.
+type Result struct {
+	Allowed bool
+	// Limit is the number of requests allowed per time frame.
+	Limit int
+	// Remaining is the number of requests left in the current time frame.
+	Remaining int
+	// RetryAfter is how long to wait before the next request is allowed,
+	// zero when Allowed.
+	RetryAfter time.Duration
+	// Reset is how long until the quota is fully restored.
+	Reset time.Duration
+}
+
+// New builds the limiter for cfg. algorithm is "fixed" or "token"; when rdb
+// is not nil the fixed window counters are kept in Redis under prefix.
+func New(cfg Config, algorithm string, rdb redis.Scripter, prefix string) (Limiter, error) {
+	switch {
+	case algorithm == "fixed" && rdb != nil:
+		return NewRedisFixedWindowLimiter(rdb, prefix, cfg.RequestsPerTimeFrame, cfg.TimeFrame), nil
+	case algorithm == "fixed":
+		return NewFixedWindowLimiter(cfg.RequestsPerTimeFrame, cfg.TimeFrame), nil
+	case algorithm == "token" && rdb == nil:
+		return NewTokenBucketLimiter(cfg.RequestsPerTimeFrame, cfg.TimeFrame), nil
+	case algorithm == "token":
+		return nil, fmt.Errorf("rate limiter algorithm %q is not supported by the redis backend", algorithm)
+	default:
+		return nil, fmt.Errorf("unknown rate limiter algorithm %q", algorithm)
+	}
+}
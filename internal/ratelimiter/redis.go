@@ -0,0 +1,68 @@
+package ratelimiter
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fixedWindowScript increments the counter of a key, starting its window on
+// the first hit, and returns the new count with the window's remaining TTL.
+var fixedWindowScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {count, ttl}
+`)
+
+// RedisFixedWindowRateLimiter is a fixed window limiter whose counters live
+// in Redis, so every API instance shares the same quota.
+type RedisFixedWindowRateLimiter struct {
+	rdb    redis.Scripter
+	prefix string
+	limit  int
+	window time.Duration
+}
+
+func NewRedisFixedWindowLimiter(rdb redis.Scripter, prefix string, limit int, window time.Duration) *RedisFixedWindowRateLimiter {
+	return &RedisFixedWindowRateLimiter{
+		rdb:    rdb,
+		prefix: prefix,
+		limit:  limit,
+		window: window,
+	}
+}
+
+func (rl *RedisFixedWindowRateLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	values, err := fixedWindowScript.Run(
+		ctx,
+		rl.rdb,
+		[]string{rl.prefix + ":" + key},
+		rl.window.Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	count, ttl := int(values[0]), time.Duration(values[1])*time.Millisecond
+
+	res := Result{
+		Limit: rl.limit,
+		Reset: ttl,
+	}
+	if count > rl.limit {
+		res.RetryAfter = ttl
+		return res, nil
+	}
+
+	res.Allowed = true
+	res.Remaining = rl.limit - count
+	return res, nil
+}
@@ -0,0 +1,51 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisFixedWindowLimiter(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	rl := NewRedisFixedWindowLimiter(rdb, "ratelimit", 2, time.Minute)
+
+	t.Run("should allow up to the limit within a window", func(t *testing.T) {
+		if res := allow(t, rl, "a"); !res.Allowed || res.Remaining != 1 || res.Reset != time.Minute {
+			t.Fatalf("expected the first request allowed with 1 left, got %+v", res)
+		}
+
+		mr.FastForward(20 * time.Second)
+		if res := allow(t, rl, "a"); !res.Allowed || res.Remaining != 0 {
+			t.Fatalf("expected the second request allowed with none left, got %+v", res)
+		}
+
+		res := allow(t, rl, "a")
+		if res.Allowed || res.RetryAfter != 40*time.Second {
+			t.Fatalf("expected to retry when the window ends in 40s, got %+v", res)
+		}
+	})
+
+	t.Run("should store counters under the prefix", func(t *testing.T) {
+		if !mr.Exists("ratelimit:a") {
+			t.Fatal("expected the counter of a under the prefix")
+		}
+		if res := allow(t, rl, "b"); !res.Allowed {
+			t.Fatal("expected another key to be allowed")
+		}
+	})
+
+	t.Run("should start a new window once the last one expires", func(t *testing.T) {
+		mr.FastForward(40 * time.Second)
+
+		res := allow(t, rl, "a")
+		if !res.Allowed || res.Remaining != 1 || res.Reset != time.Minute {
+			t.Fatalf("expected a fresh window, got %+v", res)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package ratelimiter
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// TokenBucketRateLimiter lets each key burst up to limit requests and
+// refills the bucket continuously at limit tokens per window.
+type TokenBucketRateLimiter struct {
+	sync.Mutex
+	buckets   map[string]*bucket
+	limit     int
+	window    time.Duration
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewTokenBucketLimiter(limit int, window time.Duration) *TokenBucketRateLimiter {
+	return &TokenBucketRateLimiter{
+		buckets:   make(map[string]*bucket),
+		limit:     limit,
+		window:    window,
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (rl *TokenBucketRateLimiter) Allow(_ context.Context, key string) (Result, error) {
+	rl.Lock()
+	defer rl.Unlock()
+
+	now := rl.now()
+	rl.sweep(now)
+
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rl.limit), lastRefill: now}
+		rl.buckets[key] = b
+	}
+	rl.refill(b, now)
+
+	res := Result{Limit: rl.limit}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = rl.timeFor(1 - b.tokens)
+	}
+
+	res.Remaining = int(math.Floor(b.tokens))
+	res.Reset = rl.timeFor(float64(rl.limit) - b.tokens)
+	return res, nil
+}
+
+func (rl *TokenBucketRateLimiter) refill(b *bucket, now time.Time) {
+	elapsed := now.Sub(b.lastRefill)
+	b.tokens = math.Min(float64(rl.limit), b.tokens+rl.rate()*elapsed.Seconds())
+	b.lastRefill = now
+}
+
+// rate is the number of tokens added per second.
+func (rl *TokenBucketRateLimiter) rate() float64 {
+	return float64(rl.limit) / rl.window.Seconds()
+}
+
+// timeFor returns how long it takes to refill the given number of tokens.
+func (rl *TokenBucketRateLimiter) timeFor(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(tokens / rl.rate() * float64(time.Second)))
+}
+
+// sweep drops buckets that have been idle long enough to be full again.
+func (rl *TokenBucketRateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < rl.window {
+		return
+	}
+	for key, b := range rl.buckets {
+		if now.Sub(b.lastRefill) >= rl.window {
+			delete(rl.buckets, key)
+		}
+	}
+	rl.lastSweep = now
+}
@@ -0,0 +1,55 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucketLimiter(t *testing.T) {
+	clock := newFakeClock()
+	rl := NewTokenBucketLimiter(2, time.Minute)
+	rl.now = clock.now
+
+	t.Run("should allow a burst up to the limit", func(t *testing.T) {
+		if res := allow(t, rl, "a"); !res.Allowed || res.Remaining != 1 || res.Reset != 30*time.Second {
+			t.Fatalf("expected the first request allowed with 1 left, got %+v", res)
+		}
+		if res := allow(t, rl, "a"); !res.Allowed || res.Remaining != 0 {
+			t.Fatalf("expected the second request allowed with none left, got %+v", res)
+		}
+
+		res := allow(t, rl, "a")
+		if res.Allowed {
+			t.Fatal("expected the third request to be limited")
+		}
+		if res.RetryAfter != 30*time.Second || res.Reset != time.Minute {
+			t.Fatalf("expected a token back in 30s and a full bucket in 1m, got %+v", res)
+		}
+	})
+
+	t.Run("should refill continuously", func(t *testing.T) {
+		clock.advance(15 * time.Second)
+		res := allow(t, rl, "a")
+		if res.Allowed || res.RetryAfter != 15*time.Second {
+			t.Fatalf("expected half a token and 15s to wait, got %+v", res)
+		}
+
+		clock.advance(15 * time.Second)
+		if res := allow(t, rl, "a"); !res.Allowed || res.Remaining != 0 {
+			t.Fatalf("expected the refilled token to be used, got %+v", res)
+		}
+	})
+
+	t.Run("should not refill beyond the limit", func(t *testing.T) {
+		clock.advance(10 * time.Minute)
+
+		for i := range 2 {
+			if res := allow(t, rl, "a"); !res.Allowed {
+				t.Fatalf("expected request %d of the burst to be allowed", i+1)
+			}
+		}
+		if res := allow(t, rl, "a"); res.Allowed {
+			t.Fatal("expected the bucket to hold no more than the limit")
+		}
+	})
+}
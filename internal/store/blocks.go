@@ -0,0 +1,125 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// notBlocked is the SQL condition that the user in the userID column and
+// viewer have not blocked one another.
+func notBlocked(userID, viewer string) string {
+	return fmt.Sprintf(`NOT EXISTS (
+		SELECT 1 FROM user_blocks b
+		WHERE (b.blocker_id = %[2]s AND b.blocked_id = %[1]s) OR (b.blocker_id = %[1]s AND b.blocked_id = %[2]s)
+	)`, userID, viewer)
+}
+
+// BlockStore keeps the users' blocks and mutes. A block works both ways:
+// neither user can follow the other, comment on the other's posts or see
+// them. A mute only hides the muted user's posts from the muter's feed.
+type BlockStore struct {
+	db *sql.DB
+}
+
+// Block records that blockerID blocked blockedID, removes the follows
+// between them and rejects their pending follow requests, in both
+// directions.
+func (s *BlockStore) Block(ctx context.Context, blockerID, blockedID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+		INSERT INTO user_blocks (blocker_id,blocked_id)
+		VALUES ($1,$2)
+		ON CONFLICT DO NOTHING
+		`
+		if _, err := tx.ExecContext(ctx, query, blockerID, blockedID); err != nil {
+			return err
+		}
+
+		query = `
+		DELETE FROM followers
+		WHERE (user_id = $1 AND follower_id = $2) OR (user_id = $2 AND follower_id = $1)
+		`
+		if _, err := tx.ExecContext(ctx, query, blockerID, blockedID); err != nil {
+			return err
+		}
+
+		query = `
+		UPDATE follow_requests SET status = 'rejected', updated_at = NOW()
+		WHERE status = 'pending' AND
+		((requester_id = $1 AND target_id = $2) OR (requester_id = $2 AND target_id = $1))
+		`
+		_, err := tx.ExecContext(ctx, query, blockerID, blockedID)
+		return err
+	})
+}
+
+func (s *BlockStore) Unblock(ctx context.Context, blockerID, blockedID int64) error {
+	query := `
+	DELETE FROM user_blocks
+	WHERE blocker_id = $1 AND blocked_id = $2
+	`
+	return s.remove(ctx, query, blockerID, blockedID)
+}
+
+func (s *BlockStore) Mute(ctx context.Context, muterID, mutedID int64) error {
+	query := `
+	INSERT INTO user_mutes (muter_id,muted_id)
+	VALUES ($1,$2)
+	ON CONFLICT DO NOTHING
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, muterID, mutedID)
+	return err
+}
+
+func (s *BlockStore) Unmute(ctx context.Context, muterID, mutedID int64) error {
+	query := `
+	DELETE FROM user_mutes
+	WHERE muter_id = $1 AND muted_id = $2
+	`
+	return s.remove(ctx, query, muterID, mutedID)
+}
+
+func (s *BlockStore) remove(ctx context.Context, query string, userID, otherID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, userID, otherID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// IsBlocked reports whether either user has blocked the other.
+func (s *BlockStore) IsBlocked(ctx context.Context, userID, otherID int64) (bool, error) {
+	query := `
+	SELECT EXISTS (
+		SELECT 1 FROM user_blocks
+		WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1)
+	)
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var blocked bool
+	if err := s.db.QueryRowContext(ctx, query, userID, otherID).Scan(&blocked); err != nil {
+		return false, err
+	}
+	return blocked, nil
+}
@@ -0,0 +1,23 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+// NewNoopStorage returns a Storage that never holds anything, so every read
+// goes to the wrapped store.
+func NewNoopStorage() Storage {
+	return Storage{
+		Users:    noopStore[store.User]{},
+		Posts:    noopStore[store.Post]{},
+		Sessions: noopStore[store.ActiveSessions]{},
+	}
+}
+
+type noopStore[T any] struct{}
+
+func (noopStore[T]) Get(context.Context, int64) (*T, error) { return nil, nil }
+func (noopStore[T]) Set(context.Context, *T) error          { return nil }
+func (noopStore[T]) Delete(context.Context, int64) error    { return nil }
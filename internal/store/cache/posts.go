@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sharukh010/social/internal/store"
+)
+
+type PostStore struct {
+	rdb *redis.Client
+	ttl time.Duration
+}
+
+func (s *PostStore) Get(ctx context.Context, postID int64) (*store.Post, error) {
+	var post store.Post
+	ok, err := get(ctx, s.rdb, postKey(postID), &post)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &post, nil
+}
+
+func (s *PostStore) Set(ctx context.Context, post *store.Post) error {
+	return set(ctx, s.rdb, postKey(post.ID), post, s.ttl)
+}
+
+func (s *PostStore) Delete(ctx context.Context, postID int64) error {
+	return s.rdb.Del(ctx, postKey(postID)).Err()
+}
+
+func postKey(postID int64) string {
+	return fmt.Sprintf("post-%d", postID)
+}
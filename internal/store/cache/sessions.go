@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sharukh010/social/internal/store"
+)
+
+type SessionStore struct {
+	rdb *redis.Client
+	ttl time.Duration
+}
+
+func (s *SessionStore) Get(ctx context.Context, userID int64) (*store.ActiveSessions, error) {
+	var active store.ActiveSessions
+	ok, err := get(ctx, s.rdb, sessionsKey(userID), &active)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &active, nil
+}
+
+// Set caches active for the TTL of the store, but no longer than until its
+// first family expires, so that an expired family is never read back.
+func (s *SessionStore) Set(ctx context.Context, active *store.ActiveSessions) error {
+	ttl := s.ttl
+	if !active.Expiry.IsZero() {
+		ttl = min(ttl, time.Until(active.Expiry))
+		if ttl <= 0 {
+			return nil
+		}
+	}
+	return set(ctx, s.rdb, sessionsKey(active.UserID), active, ttl)
+}
+
+func (s *SessionStore) Delete(ctx context.Context, userID int64) error {
+	return s.rdb.Del(ctx, sessionsKey(userID)).Err()
+}
+
+func sessionsKey(userID int64) string {
+	return fmt.Sprintf("sessions-%d", userID)
+}
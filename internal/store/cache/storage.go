@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sharukh010/social/internal/store"
+)
+
+// Storage caches single records by ID. Get returns a nil record and no error
+// on a cache miss.
+type Storage struct {
+	Users interface {
+		Get(context.Context, int64) (*store.User, error)
+		Set(context.Context, *store.User) error
+		Delete(context.Context, int64) error
+	}
+	Posts interface {
+		Get(context.Context, int64) (*store.Post, error)
+		Set(context.Context, *store.Post) error
+		Delete(context.Context, int64) error
+	}
+	// Sessions holds the active session families of users by user ID.
+	Sessions interface {
+		Get(context.Context, int64) (*store.ActiveSessions, error)
+		Set(context.Context, *store.ActiveSessions) error
+		Delete(context.Context, int64) error
+	}
+}
+
+func NewRedisStorage(rdb *redis.Client, ttl time.Duration) Storage {
+	return Storage{
+		Users:    &UserStore{rdb: rdb, ttl: ttl},
+		Posts:    &PostStore{rdb: rdb, ttl: ttl},
+		Sessions: &SessionStore{rdb: rdb, ttl: ttl},
+	}
+}
+
+// get decodes the gob stored under key into v and reports whether it was
+// there.
+func get(ctx context.Context, rdb *redis.Client, key string, v any) (bool, error) {
+	data, err := rdb.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// set stores v under key as a gob, which unlike JSON keeps fields hidden
+// from API responses such as the password hash.
+func set(ctx context.Context, rdb *redis.Client, key string, v any, ttl time.Duration) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return err
+	}
+	return rdb.Set(ctx, key, buf.Bytes(), ttl).Err()
+}
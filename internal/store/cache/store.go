@@ -0,0 +1,216 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+// Wrap returns s with Users.GetByID, Posts.GetByID and Sessions.GetActive
+// read through c. Writes go to s first and then drop the records they
+// touched from c.
+//
+// The cache is best effort: its errors are ignored and reads fall back to s,
+// since every entry expires on its own anyway.
+func Wrap(s store.Storage, c Storage) store.Storage {
+	s.Users = &cachedUserStore{userStore: s.Users, cache: c}
+	s.Posts = &cachedPostStore{postStore: s.Posts, cache: c}
+	s.Sessions = &cachedSessionStore{sessionStore: s.Sessions, cache: c}
+	return s
+}
+
+// userStore is the method set of store.Storage.Users.
+type userStore interface {
+	Create(context.Context, *sql.Tx, *store.User) error
+	GetByID(context.Context, int64) (*store.User, error)
+	GetByEmail(context.Context, string) (*store.User, error)
+	Follow(context.Context, int64, int64) error
+	UnFollow(context.Context, int64, int64) error
+	CreateAndInvite(context.Context, *store.User, string, time.Duration) error
+	Activate(context.Context, string) (int64, error)
+	Delete(context.Context, int64) error
+	CreatePasswordReset(context.Context, int64, string, time.Duration) error
+	ResetPassword(context.Context, string, store.Password) (int64, error)
+	SetPrivate(context.Context, int64, bool) error
+	UpdateProfile(context.Context, *store.User, time.Time) error
+}
+
+type cachedUserStore struct {
+	userStore
+	cache Storage
+}
+
+func (s *cachedUserStore) GetByID(ctx context.Context, userID int64) (*store.User, error) {
+	if user, err := s.cache.Users.Get(ctx, userID); err == nil && user != nil {
+		return user, nil
+	}
+
+	user, err := s.userStore.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.cache.Users.Set(ctx, user)
+
+	return user, nil
+}
+
+func (s *cachedUserStore) Activate(ctx context.Context, token string) (int64, error) {
+	userID, err := s.userStore.Activate(ctx, token)
+	if err != nil {
+		return 0, err
+	}
+	_ = s.cache.Users.Delete(ctx, userID)
+
+	return userID, nil
+}
+
+func (s *cachedUserStore) Delete(ctx context.Context, userID int64) error {
+	if err := s.userStore.Delete(ctx, userID); err != nil {
+		return err
+	}
+	_ = s.cache.Users.Delete(ctx, userID)
+	_ = s.cache.Sessions.Delete(ctx, userID)
+
+	return nil
+}
+
+func (s *cachedUserStore) ResetPassword(ctx context.Context, token string, password store.Password) (int64, error) {
+	userID, err := s.userStore.ResetPassword(ctx, token, password)
+	if err != nil {
+		return 0, err
+	}
+	_ = s.cache.Users.Delete(ctx, userID)
+	// resetting the password revokes every session
+	_ = s.cache.Sessions.Delete(ctx, userID)
+
+	return userID, nil
+}
+
+func (s *cachedUserStore) SetPrivate(ctx context.Context, userID int64, private bool) error {
+	if err := s.userStore.SetPrivate(ctx, userID, private); err != nil {
+		return err
+	}
+	_ = s.cache.Users.Delete(ctx, userID)
+
+	return nil
+}
+
+func (s *cachedUserStore) UpdateProfile(ctx context.Context, user *store.User, changedBefore time.Time) error {
+	if err := s.userStore.UpdateProfile(ctx, user, changedBefore); err != nil {
+		return err
+	}
+	_ = s.cache.Users.Delete(ctx, user.ID)
+
+	return nil
+}
+
+// postStore is the method set of store.Storage.Posts.
+type postStore interface {
+	Create(context.Context, *store.Post) error
+	GetByID(context.Context, int64) (*store.Post, error)
+	Delete(context.Context, int64, int) error
+	Update(context.Context, *store.Post) error
+	GetUserFeed(context.Context, int64, store.PaginatedFeedQuery) ([]store.PostWithMetadata, store.Page, error)
+	GetTrashed(context.Context, int64) (*store.Post, error)
+	Restore(context.Context, int64, time.Time) error
+	Purge(context.Context, time.Time) (int64, error)
+}
+
+type cachedPostStore struct {
+	postStore
+	cache Storage
+}
+
+func (s *cachedPostStore) GetByID(ctx context.Context, postID int64) (*store.Post, error) {
+	if post, err := s.cache.Posts.Get(ctx, postID); err == nil && post != nil {
+		return post, nil
+	}
+
+	post, err := s.postStore.GetByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.cache.Posts.Set(ctx, post)
+
+	return post, nil
+}
+
+func (s *cachedPostStore) Update(ctx context.Context, post *store.Post) error {
+	if err := s.postStore.Update(ctx, post); err != nil {
+		return err
+	}
+	_ = s.cache.Posts.Delete(ctx, post.ID)
+
+	return nil
+}
+
+func (s *cachedPostStore) Delete(ctx context.Context, postID int64, version int) error {
+	if err := s.postStore.Delete(ctx, postID, version); err != nil {
+		return err
+	}
+	_ = s.cache.Posts.Delete(ctx, postID)
+
+	return nil
+}
+
+// sessionStore is the method set of store.Storage.Sessions.
+type sessionStore interface {
+	Create(context.Context, *store.Session) error
+	GetByToken(context.Context, string) (*store.Session, error)
+	GetByUserID(context.Context, int64) ([]store.Session, error)
+	GetActive(context.Context, int64) (*store.ActiveSessions, error)
+	Rotate(context.Context, *store.Session, *store.Session) error
+	RevokeFamily(context.Context, int64, string) error
+	RevokeByID(context.Context, int64, int64) error
+}
+
+// cachedSessionStore caches the active session families checked on every
+// authenticated request. Rotating keeps the family, so only new families
+// and revocations drop the cached ones.
+type cachedSessionStore struct {
+	sessionStore
+	cache Storage
+}
+
+func (s *cachedSessionStore) GetActive(ctx context.Context, userID int64) (*store.ActiveSessions, error) {
+	if active, err := s.cache.Sessions.Get(ctx, userID); err == nil && active != nil {
+		return active, nil
+	}
+
+	active, err := s.sessionStore.GetActive(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.cache.Sessions.Set(ctx, active)
+
+	return active, nil
+}
+
+func (s *cachedSessionStore) Create(ctx context.Context, session *store.Session) error {
+	if err := s.sessionStore.Create(ctx, session); err != nil {
+		return err
+	}
+	_ = s.cache.Sessions.Delete(ctx, session.UserID)
+
+	return nil
+}
+
+func (s *cachedSessionStore) RevokeFamily(ctx context.Context, userID int64, familyID string) error {
+	if err := s.sessionStore.RevokeFamily(ctx, userID, familyID); err != nil {
+		return err
+	}
+	_ = s.cache.Sessions.Delete(ctx, userID)
+
+	return nil
+}
+
+func (s *cachedSessionStore) RevokeByID(ctx context.Context, userID, sessionID int64) error {
+	if err := s.sessionStore.RevokeByID(ctx, userID, sessionID); err != nil {
+		return err
+	}
+	_ = s.cache.Sessions.Delete(ctx, userID)
+
+	return nil
+}
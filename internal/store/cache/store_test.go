@@ -0,0 +1,214 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sharukh010/social/internal/store"
+	"github.com/sharukh010/social/internal/store/memstore"
+)
+
+func newTestStore(t *testing.T, ttl time.Duration) (store.Storage, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	return Wrap(memstore.New(), NewRedisStorage(rdb, ttl)), mr
+}
+
+// newTestUser invites a user who can be activated with token.
+func newTestUser(t *testing.T, s store.Storage, token string) *store.User {
+	t.Helper()
+
+	user := &store.User{
+		Username: "alice",
+		Email:    "alice@example.com",
+		Role:     store.Role{Name: "user"},
+	}
+	if err := user.Password.Set("password"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Users.CreateAndInvite(context.Background(), user, hashToken(token), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func TestWrapEvictsOnWrite(t *testing.T) {
+	ctx := context.Background()
+	s, mr := newTestStore(t, time.Minute)
+
+	user := newTestUser(t, s, "invitation")
+
+	post := &store.Post{Title: "Hello", Content: "World", UserID: user.ID}
+	if err := s.Posts.Create(ctx, post); err != nil {
+		t.Fatal(err)
+	}
+
+	newSession := func(familyID string) *store.Session {
+		return &store.Session{
+			UserID:   user.ID,
+			FamilyID: familyID,
+			Token:    hashToken(familyID),
+			Expiry:   time.Now().Add(time.Hour),
+		}
+	}
+	first, second := newSession("first"), newSession("second")
+
+	readUser := func() error {
+		_, err := s.Users.GetByID(ctx, user.ID)
+		return err
+	}
+	readPost := func() error {
+		_, err := s.Posts.GetByID(ctx, post.ID)
+		return err
+	}
+	readSessions := func() error {
+		_, err := s.Sessions.GetActive(ctx, user.ID)
+		return err
+	}
+
+	tests := []struct {
+		name  string
+		key   string
+		read  func() error
+		write func() error
+	}{
+		{
+			name: "Users.Activate",
+			key:  userKey(user.ID),
+			read: readUser,
+			write: func() error {
+				_, err := s.Users.Activate(ctx, "invitation")
+				return err
+			},
+		},
+		{
+			name: "Users.ResetPassword",
+			key:  userKey(user.ID),
+			read: readUser,
+			write: func() error {
+				if err := s.Users.CreatePasswordReset(ctx, user.ID, hashToken("reset"), time.Hour); err != nil {
+					return err
+				}
+				var password store.Password
+				if err := password.Set("new password"); err != nil {
+					return err
+				}
+				_, err := s.Users.ResetPassword(ctx, "reset", password)
+				return err
+			},
+		},
+		{
+			name:  "Users.SetPrivate",
+			key:   userKey(user.ID),
+			read:  readUser,
+			write: func() error { return s.Users.SetPrivate(ctx, user.ID, true) },
+		},
+		{
+			name: "Users.UpdateProfile",
+			key:  userKey(user.ID),
+			read: readUser,
+			write: func() error {
+				user.Username = "alice.smith"
+				return s.Users.UpdateProfile(ctx, user, time.Now())
+			},
+		},
+		{
+			name: "Posts.Update",
+			key:  postKey(post.ID),
+			read: readPost,
+			write: func() error {
+				post.Title = "Hello again"
+				return s.Posts.Update(ctx, post)
+			},
+		},
+		{
+			name:  "Posts.Delete",
+			key:   postKey(post.ID),
+			read:  readPost,
+			write: func() error { return s.Posts.Delete(ctx, post.ID, post.Version) },
+		},
+		{
+			name:  "Sessions.Create",
+			key:   sessionsKey(user.ID),
+			read:  readSessions,
+			write: func() error { return s.Sessions.Create(ctx, first) },
+		},
+		{
+			name:  "Sessions.RevokeByID",
+			key:   sessionsKey(user.ID),
+			read:  readSessions,
+			write: func() error { return s.Sessions.RevokeByID(ctx, user.ID, first.ID) },
+		},
+		{
+			name: "Sessions.RevokeFamily",
+			key:  sessionsKey(user.ID),
+			read: func() error {
+				if err := s.Sessions.Create(ctx, second); err != nil {
+					return err
+				}
+				return readSessions()
+			},
+			write: func() error { return s.Sessions.RevokeFamily(ctx, user.ID, second.FamilyID) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("should evict on "+tt.name, func(t *testing.T) {
+			if err := tt.read(); err != nil {
+				t.Fatal(err)
+			}
+			if !mr.Exists(tt.key) {
+				t.Fatalf("expected %s to be cached", tt.key)
+			}
+
+			if err := tt.write(); err != nil {
+				t.Fatal(err)
+			}
+			if mr.Exists(tt.key) {
+				t.Fatalf("expected %s to be evicted", tt.key)
+			}
+		})
+	}
+}
+
+func TestWrapCapsSessionsTTL(t *testing.T) {
+	ctx := context.Background()
+	s, mr := newTestStore(t, time.Hour)
+
+	user := newTestUser(t, s, "invitation")
+	session := &store.Session{
+		UserID:   user.ID,
+		FamilyID: "family",
+		Token:    hashToken("refresh"),
+		Expiry:   time.Now().Add(30 * time.Second),
+	}
+	if err := s.Sessions.Create(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Sessions.GetActive(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL(sessionsKey(user.ID)); ttl <= 0 || ttl > 30*time.Second {
+		t.Fatalf("expected the sessions to be cached until the session expires in 30s, got a TTL of %s", ttl)
+	}
+
+	mr.FastForward(30 * time.Second)
+	if mr.Exists(sessionsKey(user.ID)) {
+		t.Fatal("expected the cached sessions to expire with the session")
+	}
+}
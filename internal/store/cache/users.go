@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sharukh010/social/internal/store"
+)
+
+type UserStore struct {
+	rdb *redis.Client
+	ttl time.Duration
+}
+
+func (s *UserStore) Get(ctx context.Context, userID int64) (*store.User, error) {
+	var user store.User
+	ok, err := get(ctx, s.rdb, userKey(userID), &user)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *UserStore) Set(ctx context.Context, user *store.User) error {
+	return set(ctx, s.rdb, userKey(user.ID), user, s.ttl)
+}
+
+func (s *UserStore) Delete(ctx context.Context, userID int64) error {
+	return s.rdb.Del(ctx, userKey(userID)).Err()
+}
+
+func userKey(userID int64) string {
+	return fmt.Sprintf("user-%d", userID)
+}
@@ -3,27 +3,143 @@ package store
 import (
 	"context"
 	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type Comment struct {
-	ID        int64  `json:"id"`
-	PostID    int64  `json:"post_id"`
-	UserID    int64  `json:"user_id"`
-	Content   string `json:"content"`
-	CreatedAt string `json:"created_at"`
-	User      User   `json:"user"`
+	ID         int64     `json:"id"`
+	PostID     int64     `json:"post_id"`
+	UserID     int64     `json:"user_id"`
+	ParentID   *int64    `json:"parent_id"`
+	Depth      int       `json:"depth"`
+	Path       []int64   `json:"path,omitempty"`
+	Content    string    `json:"content"`
+	CreatedAt  string    `json:"created_at"`
+	ReplyCount int       `json:"reply_count"`
+	Replies    []Comment `json:"replies,omitempty"`
+	User       User      `json:"user"`
+	// DeletedAt is set while the comment is in the trash.
+	DeletedAt *string `json:"deleted_at,omitempty"`
+}
+
+// CommentQuery selects one page of comments under ParentID (top-level
+// comments when nil), each with up to Replies replies nested Depth levels
+// deep. Comments by users ViewerID has blocked, or who blocked ViewerID,
+// are left out.
+type CommentQuery struct {
+	ParentID *int64 `json:"-"`
+	ViewerID int64  `json:"-"`
+	Limit    int    `json:"limit" validate:"gte=1,lte=50"`
+	Sort     string `json:"sort" validate:"oneof=asc desc"`
+	Format   string `json:"format" validate:"oneof=tree flat"`
+	Depth    int    `json:"depth" validate:"gte=0,lte=10"`
+	Replies  int    `json:"replies" validate:"gte=0,lte=20"`
+	Cursor   string `json:"cursor" validate:"max=512"`
+	// Position is the decoded Cursor, nil for the first page.
+	Position *Cursor `json:"-"`
+}
+
+func (cq CommentQuery) Parse(r *http.Request) (CommentQuery, error) {
+	qs := r.URL.Query()
+
+	for param, dst := range map[string]*int{
+		"limit":   &cq.Limit,
+		"depth":   &cq.Depth,
+		"replies": &cq.Replies,
+	} {
+		value := qs.Get(param)
+		if value == "" {
+			continue
+		}
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return cq, err
+		}
+		*dst = v
+	}
+
+	sort := qs.Get("sort")
+	if sort != "" {
+		cq.Sort = sort
+	}
+
+	format := qs.Get("format")
+	if format != "" {
+		cq.Format = format
+	}
+
+	cursor := qs.Get("cursor")
+	if cursor != "" {
+		cq.Cursor = cursor
+	}
+
+	return cq, nil
+}
+
+func (cq CommentQuery) DecodeCursor(secret []byte) (CommentQuery, error) {
+	if cq.Cursor == "" {
+		return cq, nil
+	}
+
+	c, err := DecodeCursor(cq.Cursor, secret)
+	if err != nil {
+		return cq, err
+	}
+	cq.Position = c
+
+	return cq, nil
+}
+
+func (cq CommentQuery) FeedQuery() PaginatedFeedQuery {
+	return PaginatedFeedQuery{
+		Limit:    cq.Limit,
+		Sort:     cq.Sort,
+		Position: cq.Position,
+	}
+}
+
+// FlattenComments walks a comment tree depth first and returns every
+// comment without its nested Replies, with Path set to the IDs leading to
+// it from the top of the tree. path is the prefix to start from.
+func FlattenComments(comments []Comment, path []int64) []Comment {
+	flat := []Comment{}
+	for _, c := range comments {
+		c.Path = append(append([]int64{}, path...), c.ID)
+		replies := c.Replies
+		c.Replies = nil
+
+		flat = append(flat, c)
+		flat = append(flat, FlattenComments(replies, c.Path)...)
+	}
+	return flat
 }
 
 type CommentStore struct {
 	db *sql.DB
 }
 
+// Create reports ErrBlocked if the commenter and the author of the post or
+// of the parent comment have blocked one another, and ErrNotFound if the
+// visibility of the post hides it from the commenter.
 func (s *CommentStore) Create(ctx context.Context, comment *Comment) error {
 	query := `
-	INSERT INTO comments 
-	(post_id,user_id,content)
-	VALUES 
-	($1,$2,$3) RETURNING id,created_at 
+	INSERT INTO comments
+	(post_id,user_id,content,parent_id,depth)
+	SELECT $1::bigint,$2::bigint,$3::text,$4::bigint,$5::int
+	WHERE NOT EXISTS (
+		SELECT 1 FROM user_blocks b
+		JOIN (
+			SELECT user_id FROM posts WHERE id = $1
+			UNION SELECT user_id FROM comments WHERE id = $4
+		) a ON (b.blocker_id = $2 AND b.blocked_id = a.user_id) OR (b.blocker_id = a.user_id AND b.blocked_id = $2)
+	) AND EXISTS (
+		SELECT 1 FROM posts p WHERE p.id = $1 AND ` + visibleTo("p", "$2") + `
+	)
+	RETURNING id,created_at
 	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
@@ -34,52 +150,214 @@ func (s *CommentStore) Create(ctx context.Context, comment *Comment) error {
 		comment.PostID,
 		comment.UserID,
 		comment.Content,
+		comment.ParentID,
+		comment.Depth,
 	).Scan(
 		&comment.ID,
 		&comment.CreatedAt,
 	)
 	comment.User.ID = comment.UserID
 	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return s.createRefused(ctx, comment)
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// createRefused tells why comment was not inserted: ErrNotFound when its
+// post is hidden from the commenter, ErrBlocked otherwise.
+func (s *CommentStore) createRefused(ctx context.Context, comment *Comment) error {
+	query := `SELECT EXISTS (SELECT 1 FROM posts p WHERE p.id = $1 AND ` + visibleTo("p", "$2") + `)`
+
+	var visible bool
+	if err := s.db.QueryRowContext(ctx, query, comment.PostID, comment.UserID).Scan(&visible); err != nil {
 		return err
 	}
+	if !visible {
+		return ErrNotFound
+	}
+	return ErrBlocked
+}
+
+func (s *CommentStore) GetByID(ctx context.Context, commentID int64) (*Comment, error) {
+	query := `
+	SELECT c.id,c.post_id,c.user_id,c.parent_id,c.depth,c.content,c.created_at,users.username,
+	users.id,(SELECT count(*) FROM comments r WHERE r.parent_id = c.id AND r.deleted_at IS NULL) FROM
+	comments c JOIN users on users.id = c.user_id
+	WHERE c.id = $1 AND c.deleted_at IS NULL
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var c Comment
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		commentID,
+	).Scan(&c.ID, &c.PostID, &c.UserID, &c.ParentID, &c.Depth, &c.Content, &c.CreatedAt, &c.User.Username, &c.User.ID, &c.ReplyCount)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &c, nil
+}
+
+// GetByPostID returns a page of the comments selected by cq, with their
+// replies nested cq.Depth levels deep. Replies are capped at cq.Replies per
+// comment; ReplyCount tells how many there are in total.
+func (s *CommentStore) GetByPostID(ctx context.Context, postID int64, cq CommentQuery) ([]Comment, Page, error) {
+	comments, page, err := s.getPage(ctx, postID, cq)
+	if err != nil {
+		return nil, Page{}, err
+	}
+
+	if err := NestReplies(ctx, comments, cq, s.getReplies); err != nil {
+		return nil, Page{}, err
+	}
+
+	return comments, page, nil
+}
+
+// NestReplies fills in the Replies of comments cq.Depth levels deep, fetching
+// each level with getReplies.
+func NestReplies(ctx context.Context, comments []Comment, cq CommentQuery, getReplies func(context.Context, []int64, int, int64) (map[int64][]Comment, error)) error {
+	level := make([]*Comment, len(comments))
+	for i := range comments {
+		level[i] = &comments[i]
+	}
+
+	for depth := 0; depth < cq.Depth && cq.Replies > 0; depth++ {
+		parentIDs := []int64{}
+		for _, c := range level {
+			if c.ReplyCount > 0 {
+				parentIDs = append(parentIDs, c.ID)
+			}
+		}
+		if len(parentIDs) == 0 {
+			break
+		}
+
+		replies, err := getReplies(ctx, parentIDs, cq.Replies, cq.ViewerID)
+		if err != nil {
+			return err
+		}
+
+		next := []*Comment{}
+		for _, c := range level {
+			c.Replies = replies[c.ID]
+			for i := range c.Replies {
+				next = append(next, &c.Replies[i])
+			}
+		}
+		level = next
+	}
+
 	return nil
 }
-func (s *CommentStore) GetByPostID(ctx context.Context, postID int64) ([]Comment, error) {
+
+func (s *CommentStore) getPage(ctx context.Context, postID int64, cq CommentQuery) ([]Comment, Page, error) {
+	fq := cq.FeedQuery()
+	op, order := fq.Keyset()
 	query := `
-	SELECT c.id,c.post_id,c.user_id,c.content,c.created_at,users.username,
-	users.id FROM 
-	comments c JOIN users on users.id = c.user_id 
-	Where c.post_id = $1
-	order by c.created_at DESC
+	SELECT c.id,c.post_id,c.user_id,c.parent_id,c.depth,c.content,c.created_at,users.username,
+	users.id,(SELECT count(*) FROM comments r WHERE r.parent_id = c.id AND r.deleted_at IS NULL AND ` + notBlocked("r.user_id", "$6") + `) FROM
+	comments c JOIN users on users.id = c.user_id
+	Where c.post_id = $1 AND c.deleted_at IS NULL AND
+	(($2::bigint IS NULL AND c.parent_id IS NULL) OR c.parent_id = $2) AND
+	` + notBlocked("c.user_id", "$6") + ` AND
+	($4::timestamptz IS NULL OR (c.created_at, c.id) ` + op + ` ($4, $5::bigint))
+	order by c.created_at ` + order + `, c.id ` + order + `
+	limit $3
 	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
+	var afterTime, afterID any
+	if cq.Position != nil {
+		afterTime = cq.Position.CreatedAt
+		afterID = cq.Position.ID
+	}
+
 	rows, err := s.db.QueryContext(
 		ctx,
 		query,
 		postID,
+		cq.ParentID,
+		cq.Limit+1,
+		afterTime,
+		afterID,
+		cq.ViewerID,
 	)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	defer rows.Close()
+	comments := []Comment{}
+	for rows.Next() {
+		var c Comment
+		if err := rows.Scan(&c.ID, &c.PostID, &c.UserID, &c.ParentID, &c.Depth, &c.Content, &c.CreatedAt, &c.User.Username, &c.User.ID, &c.ReplyCount); err != nil {
+			return nil, Page{}, err
+		}
+		comments = append(comments, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+
+	return Paginate(comments, fq, CommentCursor)
+}
+
+// getReplies returns the oldest limit replies of each parent that viewerID
+// may see, keyed by parent ID.
+func (s *CommentStore) getReplies(ctx context.Context, parentIDs []int64, limit int, viewerID int64) (map[int64][]Comment, error) {
+	query := `
+	SELECT c.id,c.post_id,c.user_id,c.parent_id,c.depth,c.content,c.created_at,c.username,
+	c.author_id,c.reply_count
+	FROM unnest($1::bigint[]) AS parent(id)
+	CROSS JOIN LATERAL (
+		SELECT r.id,r.post_id,r.user_id,r.parent_id,r.depth,r.content,r.created_at,
+		users.username,users.id AS author_id,
+		(SELECT count(*) FROM comments rr WHERE rr.parent_id = r.id AND rr.deleted_at IS NULL AND ` + notBlocked("rr.user_id", "$3") + `) AS reply_count
+		FROM comments r JOIN users on users.id = r.user_id
+		WHERE r.parent_id = parent.id AND r.deleted_at IS NULL AND ` + notBlocked("r.user_id", "$3") + `
+		ORDER BY r.created_at ASC, r.id ASC
+		LIMIT $2
+	) c
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(parentIDs), limit, viewerID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var comments []Comment
+
+	replies := map[int64][]Comment{}
 	for rows.Next() {
 		var c Comment
-		c.User = User{}
-		if err := rows.Scan(&c.ID, &c.PostID, &c.UserID, &c.Content, &c.CreatedAt, &c.User.Username, &c.User.ID); err != nil {
+		if err := rows.Scan(&c.ID, &c.PostID, &c.UserID, &c.ParentID, &c.Depth, &c.Content, &c.CreatedAt, &c.User.Username, &c.User.ID, &c.ReplyCount); err != nil {
 			return nil, err
 		}
-		comments = append(comments, c)
+		replies[*c.ParentID] = append(replies[*c.ParentID], c)
 	}
-	return comments, nil
+	return replies, rows.Err()
 }
 
+// Delete moves the comment to the trash, which hides its replies with it
+// until it is restored or purged.
 func (s *CommentStore) Delete(ctx context.Context, commentID int64) error {
 	query := `
-	DELETE from posts 
-	WHERE id = $1
+	UPDATE comments SET deleted_at = NOW()
+	WHERE id = $1 AND deleted_at IS NULL
 	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
@@ -93,8 +371,88 @@ func (s *CommentStore) Delete(ctx context.Context, commentID int64) error {
 		return err
 	}
 	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetTrashed returns the comment if it is in the trash.
+func (s *CommentStore) GetTrashed(ctx context.Context, commentID int64) (*Comment, error) {
+	query := `
+	SELECT c.id,c.post_id,c.user_id,c.parent_id,c.depth,c.content,c.created_at,c.deleted_at,
+	users.username,users.id FROM
+	comments c JOIN users on users.id = c.user_id
+	WHERE c.id = $1 AND c.deleted_at IS NOT NULL
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var c Comment
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		commentID,
+	).Scan(&c.ID, &c.PostID, &c.UserID, &c.ParentID, &c.Depth, &c.Content, &c.CreatedAt, &c.DeletedAt, &c.User.Username, &c.User.ID)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &c, nil
+}
+
+// Restore takes the comment back out of the trash, provided it was deleted
+// after deletedSince.
+func (s *CommentStore) Restore(ctx context.Context, commentID int64, deletedSince time.Time) error {
+	query := `
+	UPDATE comments SET deleted_at = NULL
+	WHERE id = $1 AND deleted_at >= $2
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, commentID, deletedSince)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
 	if rows == 0 {
 		return ErrNotFound
 	}
 	return nil
 }
+
+// Purge permanently deletes the comments trashed before deletedBefore along
+// with their replies, and returns how many comments were in the trash.
+func (s *CommentStore) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	query := `
+	DELETE FROM comments
+	WHERE deleted_at < $1
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func CommentCursor(comment Comment) (Cursor, error) {
+	createdAt, err := time.Parse(time.RFC3339, comment.CreatedAt)
+	if err != nil {
+		return Cursor{}, err
+	}
+	return Cursor{CreatedAt: createdAt, ID: comment.ID}, nil
+}
@@ -0,0 +1,208 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	FollowRequestPending  = "pending"
+	FollowRequestApproved = "approved"
+	FollowRequestRejected = "rejected"
+)
+
+// FollowRequest asks a private account, the target, to let the requester
+// follow it.
+type FollowRequest struct {
+	RequesterID int64  `json:"requester_id"`
+	TargetID    int64  `json:"target_id"`
+	Status      string `json:"status"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+	Requester   User   `json:"requester"`
+}
+
+func FollowRequestPosition(fr FollowRequest) (Cursor, error) {
+	createdAt, err := time.Parse(time.RFC3339, fr.CreatedAt)
+	if err != nil {
+		return Cursor{}, err
+	}
+	return Cursor{CreatedAt: createdAt, ID: fr.RequesterID}, nil
+}
+
+type FollowRequestStore struct {
+	db *sql.DB
+}
+
+// Create makes the request pending, whatever became of an earlier request
+// between the same users. It reports ErrBlocked if either user has blocked
+// the other.
+func (s *FollowRequestStore) Create(ctx context.Context, fr *FollowRequest) error {
+	query := `
+	INSERT INTO follow_requests (requester_id,target_id)
+	SELECT $1::bigint,$2::bigint
+	WHERE NOT EXISTS (
+		SELECT 1 FROM user_blocks
+		WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1)
+	)
+	ON CONFLICT (requester_id,target_id) DO UPDATE
+	SET status = 'pending',
+	created_at = CASE WHEN follow_requests.status = 'pending' THEN follow_requests.created_at ELSE NOW() END,
+	updated_at = NOW()
+	RETURNING status,created_at,updated_at
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		fr.RequesterID,
+		fr.TargetID,
+	).Scan(
+		&fr.Status,
+		&fr.CreatedAt,
+		&fr.UpdatedAt,
+	)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return ErrBlocked
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPending returns a page of the pending requests to follow targetID,
+// most recent first.
+func (s *FollowRequestStore) GetPending(ctx context.Context, targetID int64, fq FollowQuery) ([]FollowRequest, Page, error) {
+	feed := fq.FeedQuery()
+	op, order := feed.Keyset()
+	query := `
+	SELECT fr.requester_id,fr.target_id,fr.status,fr.created_at,fr.updated_at,u.username
+	FROM follow_requests fr
+	JOIN users u ON u.id = fr.requester_id
+	WHERE fr.target_id = $1 AND fr.status = 'pending' AND
+	($3::timestamptz IS NULL OR (fr.created_at, fr.requester_id) ` + op + ` ($3, $4::bigint))
+	ORDER BY fr.created_at ` + order + `, fr.requester_id ` + order + `
+	LIMIT $2
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var afterTime, afterID any
+	if fq.Position != nil {
+		afterTime = fq.Position.CreatedAt
+		afterID = fq.Position.ID
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, targetID, fq.Limit+1, afterTime, afterID)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	defer rows.Close()
+
+	requests := []FollowRequest{}
+	for rows.Next() {
+		var fr FollowRequest
+		if err := rows.Scan(&fr.RequesterID, &fr.TargetID, &fr.Status, &fr.CreatedAt, &fr.UpdatedAt, &fr.Requester.Username); err != nil {
+			return nil, Page{}, err
+		}
+		fr.Requester.ID = fr.RequesterID
+		requests = append(requests, fr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+
+	return Paginate(requests, feed, FollowRequestPosition)
+}
+
+// Approve accepts the pending request of requesterID to follow targetID and
+// makes them a follower.
+func (s *FollowRequestStore) Approve(ctx context.Context, targetID, requesterID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return approveFollowRequests(ctx, tx, targetID, &requesterID)
+	})
+}
+
+// Reject turns down the pending request of requesterID to follow targetID.
+func (s *FollowRequestStore) Reject(ctx context.Context, targetID, requesterID int64) error {
+	query := `
+	UPDATE follow_requests SET status = 'rejected', updated_at = NOW()
+	WHERE target_id = $1 AND requester_id = $2 AND status = 'pending'
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, targetID, requesterID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// approveFollowRequests approves the pending requests to follow targetID,
+// only the one of requesterID unless it is nil, and adds the requesters to
+// the followers. It reports ErrNotFound when requesterID has no pending
+// request. Requesters blocked by or blocking targetID never become followers.
+func approveFollowRequests(ctx context.Context, tx *sql.Tx, targetID int64, requesterID *int64) error {
+	query := `
+	UPDATE follow_requests SET status = 'approved', updated_at = NOW()
+	WHERE target_id = $1 AND status = 'pending' AND ($2::bigint IS NULL OR requester_id = $2)
+	RETURNING requester_id
+	`
+	rows, err := tx.QueryContext(ctx, query, targetID, requesterID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	requesterIDs := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		requesterIDs = append(requesterIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(requesterIDs) == 0 {
+		if requesterID != nil {
+			return ErrNotFound
+		}
+		return nil
+	}
+
+	query = `
+	INSERT INTO followers (user_id,follower_id)
+	SELECT requester.id,$2 FROM unnest($1::bigint[]) AS requester(id)
+	WHERE NOT EXISTS (
+		SELECT 1 FROM user_blocks b
+		WHERE (b.blocker_id = requester.id AND b.blocked_id = $2) OR (b.blocker_id = $2 AND b.blocked_id = requester.id)
+	)
+	ON CONFLICT DO NOTHING
+	`
+	_, err = tx.ExecContext(ctx, query, pq.Array(requesterIDs), targetID)
+	return err
+}
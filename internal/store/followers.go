@@ -0,0 +1,212 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Follow is one side of a follow relationship: User is the other user and
+// FollowedAt when the follow was made.
+type Follow struct {
+	User       User   `json:"user"`
+	FollowedAt string `json:"followed_at"`
+}
+
+// FollowStats counts the followers of a user and the users they follow.
+type FollowStats struct {
+	Followers int `json:"followers_count"`
+	Following int `json:"following_count"`
+}
+
+// Relationship tells how a user and the user viewing them follow each other.
+type Relationship struct {
+	IsFollowing bool `json:"is_following"`
+	FollowsYou  bool `json:"follows_you"`
+}
+
+// FollowQuery selects one page of a follow list. Users ViewerID has
+// blocked, or who blocked ViewerID, are left out.
+type FollowQuery struct {
+	ViewerID int64  `json:"-"`
+	Limit    int    `json:"limit" validate:"gte=1,lte=50"`
+	Cursor   string `json:"cursor" validate:"max=512"`
+	// Position is the decoded Cursor, nil for the first page.
+	Position *Cursor `json:"-"`
+}
+
+func (fq FollowQuery) Parse(r *http.Request) (FollowQuery, error) {
+	qs := r.URL.Query()
+
+	limit := qs.Get("limit")
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return fq, err
+		}
+		fq.Limit = l
+	}
+
+	cursor := qs.Get("cursor")
+	if cursor != "" {
+		fq.Cursor = cursor
+	}
+
+	return fq, nil
+}
+
+func (fq FollowQuery) DecodeCursor(secret []byte) (FollowQuery, error) {
+	if fq.Cursor == "" {
+		return fq, nil
+	}
+
+	c, err := DecodeCursor(fq.Cursor, secret)
+	if err != nil {
+		return fq, err
+	}
+	fq.Position = c
+
+	return fq, nil
+}
+
+func (fq FollowQuery) FeedQuery() PaginatedFeedQuery {
+	return PaginatedFeedQuery{
+		Limit:    fq.Limit,
+		Sort:     "desc",
+		Position: fq.Position,
+	}
+}
+
+func FollowPosition(f Follow) (Cursor, error) {
+	followedAt, err := time.Parse(time.RFC3339, f.FollowedAt)
+	if err != nil {
+		return Cursor{}, err
+	}
+	return Cursor{CreatedAt: followedAt, ID: f.User.ID}, nil
+}
+
+// FollowerStore reads the followers table. A row (user_id, follower_id)
+// records that user_id follows follower_id, as written by UserStore.Follow.
+type FollowerStore struct {
+	db *sql.DB
+}
+
+// GetFollowers returns a page of the users following userID, most recent
+// first.
+func (s *FollowerStore) GetFollowers(ctx context.Context, userID int64, fq FollowQuery) ([]Follow, Page, error) {
+	op, order := fq.FeedQuery().Keyset()
+	query := `
+	SELECT u.id,u.username,f.created_at
+	FROM followers f
+	JOIN users u ON u.id = f.user_id
+	WHERE f.follower_id = $1 AND ` + notBlocked("u.id", "$5") + ` AND
+	($3::timestamptz IS NULL OR (f.created_at, f.user_id) ` + op + ` ($3, $4::bigint))
+	ORDER BY f.created_at ` + order + `, f.user_id ` + order + `
+	LIMIT $2
+	`
+	return s.list(ctx, query, userID, fq)
+}
+
+// GetFollowing returns a page of the users userID follows, most recent
+// first.
+func (s *FollowerStore) GetFollowing(ctx context.Context, userID int64, fq FollowQuery) ([]Follow, Page, error) {
+	op, order := fq.FeedQuery().Keyset()
+	query := `
+	SELECT u.id,u.username,f.created_at
+	FROM followers f
+	JOIN users u ON u.id = f.follower_id
+	WHERE f.user_id = $1 AND ` + notBlocked("u.id", "$5") + ` AND
+	($3::timestamptz IS NULL OR (f.created_at, f.follower_id) ` + op + ` ($3, $4::bigint))
+	ORDER BY f.created_at ` + order + `, f.follower_id ` + order + `
+	LIMIT $2
+	`
+	return s.list(ctx, query, userID, fq)
+}
+
+// GetMutuals returns a page of the users userID follows who follow them
+// back, ordered by when userID followed them, most recent first.
+func (s *FollowerStore) GetMutuals(ctx context.Context, userID int64, fq FollowQuery) ([]Follow, Page, error) {
+	op, order := fq.FeedQuery().Keyset()
+	query := `
+	SELECT u.id,u.username,f.created_at
+	FROM followers f
+	JOIN followers b ON b.user_id = f.follower_id AND b.follower_id = f.user_id
+	JOIN users u ON u.id = f.follower_id
+	WHERE f.user_id = $1 AND ` + notBlocked("u.id", "$5") + ` AND
+	($3::timestamptz IS NULL OR (f.created_at, f.follower_id) ` + op + ` ($3, $4::bigint))
+	ORDER BY f.created_at ` + order + `, f.follower_id ` + order + `
+	LIMIT $2
+	`
+	return s.list(ctx, query, userID, fq)
+}
+
+// list runs one of the follow list queries, which take userID, the page
+// size, the keyset position and the viewer and select the other user and
+// the follow time.
+func (s *FollowerStore) list(ctx context.Context, query string, userID int64, fq FollowQuery) ([]Follow, Page, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var afterTime, afterID any
+	if fq.Position != nil {
+		afterTime = fq.Position.CreatedAt
+		afterID = fq.Position.ID
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, userID, fq.Limit+1, afterTime, afterID, fq.ViewerID)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	defer rows.Close()
+
+	follows := []Follow{}
+	for rows.Next() {
+		var f Follow
+		if err := rows.Scan(&f.User.ID, &f.User.Username, &f.FollowedAt); err != nil {
+			return nil, Page{}, err
+		}
+		follows = append(follows, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+
+	return Paginate(follows, fq.FeedQuery(), FollowPosition)
+}
+
+// GetStats counts the followers of userID and the users they follow.
+func (s *FollowerStore) GetStats(ctx context.Context, userID int64) (*FollowStats, error) {
+	query := `
+	SELECT
+	(SELECT count(*) FROM followers WHERE follower_id = $1),
+	(SELECT count(*) FROM followers WHERE user_id = $1)
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var stats FollowStats
+	if err := s.db.QueryRowContext(ctx, query, userID).Scan(&stats.Followers, &stats.Following); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetRelationship tells whether viewerID follows userID and the other way
+// around.
+func (s *FollowerStore) GetRelationship(ctx context.Context, userID, viewerID int64) (*Relationship, error) {
+	query := `
+	SELECT
+	EXISTS (SELECT 1 FROM followers WHERE user_id = $2 AND follower_id = $1),
+	EXISTS (SELECT 1 FROM followers WHERE user_id = $1 AND follower_id = $2)
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var rel Relationship
+	if err := s.db.QueryRowContext(ctx, query, userID, viewerID).Scan(&rel.IsFollowing, &rel.FollowsYou); err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
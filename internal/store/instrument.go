@@ -0,0 +1,490 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Hook is called before every store method with the method name, such as
+// "Posts.GetByID". The returned context is passed on to the method and done
+// is called with the method's error once it returns.
+type Hook func(ctx context.Context, method string) (_ context.Context, done func(error))
+
+// Instrument wraps every method of s with hook.
+func Instrument(s Storage, hook Hook) Storage {
+	return Storage{
+		Posts:          &instrumentedPostStore{s, hook},
+		Users:          &instrumentedUserStore{s, hook},
+		Comments:       &instrumentedCommentStore{s, hook},
+		Followers:      &instrumentedFollowerStore{s, hook},
+		Blocks:         &instrumentedBlockStore{s, hook},
+		FollowRequests: &instrumentedFollowRequestStore{s, hook},
+		Revisions:      &instrumentedRevisionStore{s, hook},
+		Reactions:      &instrumentedReactionStore{s, hook},
+		Search:         &instrumentedSearchStore{s, hook},
+		Roles:          &instrumentedRoleStore{s, hook},
+		Sessions:       &instrumentedSessionStore{s, hook},
+	}
+}
+
+type instrumentedPostStore struct {
+	next Storage
+	hook Hook
+}
+
+func (s *instrumentedPostStore) Create(ctx context.Context, post *Post) error {
+	ctx, done := s.hook(ctx, "Posts.Create")
+	err := s.next.Posts.Create(ctx, post)
+	done(err)
+	return err
+}
+
+func (s *instrumentedPostStore) GetByID(ctx context.Context, postID int64) (*Post, error) {
+	ctx, done := s.hook(ctx, "Posts.GetByID")
+	post, err := s.next.Posts.GetByID(ctx, postID)
+	done(err)
+	return post, err
+}
+
+func (s *instrumentedPostStore) Delete(ctx context.Context, postID int64, version int) error {
+	ctx, done := s.hook(ctx, "Posts.Delete")
+	err := s.next.Posts.Delete(ctx, postID, version)
+	done(err)
+	return err
+}
+
+func (s *instrumentedPostStore) Update(ctx context.Context, post *Post) error {
+	ctx, done := s.hook(ctx, "Posts.Update")
+	err := s.next.Posts.Update(ctx, post)
+	done(err)
+	return err
+}
+
+func (s *instrumentedPostStore) GetUserFeed(ctx context.Context, userID int64, fq PaginatedFeedQuery) ([]PostWithMetadata, Page, error) {
+	ctx, done := s.hook(ctx, "Posts.GetUserFeed")
+	feed, page, err := s.next.Posts.GetUserFeed(ctx, userID, fq)
+	done(err)
+	return feed, page, err
+}
+
+func (s *instrumentedPostStore) GetTrashed(ctx context.Context, postID int64) (*Post, error) {
+	ctx, done := s.hook(ctx, "Posts.GetTrashed")
+	post, err := s.next.Posts.GetTrashed(ctx, postID)
+	done(err)
+	return post, err
+}
+
+func (s *instrumentedPostStore) Restore(ctx context.Context, postID int64, deletedSince time.Time) error {
+	ctx, done := s.hook(ctx, "Posts.Restore")
+	err := s.next.Posts.Restore(ctx, postID, deletedSince)
+	done(err)
+	return err
+}
+
+func (s *instrumentedPostStore) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	ctx, done := s.hook(ctx, "Posts.Purge")
+	n, err := s.next.Posts.Purge(ctx, deletedBefore)
+	done(err)
+	return n, err
+}
+
+type instrumentedUserStore struct {
+	next Storage
+	hook Hook
+}
+
+func (s *instrumentedUserStore) Create(ctx context.Context, tx *sql.Tx, user *User) error {
+	ctx, done := s.hook(ctx, "Users.Create")
+	err := s.next.Users.Create(ctx, tx, user)
+	done(err)
+	return err
+}
+
+func (s *instrumentedUserStore) GetByID(ctx context.Context, userID int64) (*User, error) {
+	ctx, done := s.hook(ctx, "Users.GetByID")
+	user, err := s.next.Users.GetByID(ctx, userID)
+	done(err)
+	return user, err
+}
+
+func (s *instrumentedUserStore) GetByEmail(ctx context.Context, email string) (*User, error) {
+	ctx, done := s.hook(ctx, "Users.GetByEmail")
+	user, err := s.next.Users.GetByEmail(ctx, email)
+	done(err)
+	return user, err
+}
+
+func (s *instrumentedUserStore) Follow(ctx context.Context, followerUserID, followingUserID int64) error {
+	ctx, done := s.hook(ctx, "Users.Follow")
+	err := s.next.Users.Follow(ctx, followerUserID, followingUserID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedUserStore) UnFollow(ctx context.Context, followerUserID, followingUserID int64) error {
+	ctx, done := s.hook(ctx, "Users.UnFollow")
+	err := s.next.Users.UnFollow(ctx, followerUserID, followingUserID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedUserStore) CreateAndInvite(ctx context.Context, user *User, token string, invitationExp time.Duration) error {
+	ctx, done := s.hook(ctx, "Users.CreateAndInvite")
+	err := s.next.Users.CreateAndInvite(ctx, user, token, invitationExp)
+	done(err)
+	return err
+}
+
+func (s *instrumentedUserStore) Activate(ctx context.Context, token string) (int64, error) {
+	ctx, done := s.hook(ctx, "Users.Activate")
+	userID, err := s.next.Users.Activate(ctx, token)
+	done(err)
+	return userID, err
+}
+
+func (s *instrumentedUserStore) Delete(ctx context.Context, userID int64) error {
+	ctx, done := s.hook(ctx, "Users.Delete")
+	err := s.next.Users.Delete(ctx, userID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedUserStore) CreatePasswordReset(ctx context.Context, userID int64, token string, exp time.Duration) error {
+	ctx, done := s.hook(ctx, "Users.CreatePasswordReset")
+	err := s.next.Users.CreatePasswordReset(ctx, userID, token, exp)
+	done(err)
+	return err
+}
+
+func (s *instrumentedUserStore) ResetPassword(ctx context.Context, token string, password Password) (int64, error) {
+	ctx, done := s.hook(ctx, "Users.ResetPassword")
+	userID, err := s.next.Users.ResetPassword(ctx, token, password)
+	done(err)
+	return userID, err
+}
+
+func (s *instrumentedUserStore) SetPrivate(ctx context.Context, userID int64, private bool) error {
+	ctx, done := s.hook(ctx, "Users.SetPrivate")
+	err := s.next.Users.SetPrivate(ctx, userID, private)
+	done(err)
+	return err
+}
+
+func (s *instrumentedUserStore) UpdateProfile(ctx context.Context, user *User, changedBefore time.Time) error {
+	ctx, done := s.hook(ctx, "Users.UpdateProfile")
+	err := s.next.Users.UpdateProfile(ctx, user, changedBefore)
+	done(err)
+	return err
+}
+
+type instrumentedCommentStore struct {
+	next Storage
+	hook Hook
+}
+
+func (s *instrumentedCommentStore) Create(ctx context.Context, comment *Comment) error {
+	ctx, done := s.hook(ctx, "Comments.Create")
+	err := s.next.Comments.Create(ctx, comment)
+	done(err)
+	return err
+}
+
+func (s *instrumentedCommentStore) GetByID(ctx context.Context, commentID int64) (*Comment, error) {
+	ctx, done := s.hook(ctx, "Comments.GetByID")
+	comment, err := s.next.Comments.GetByID(ctx, commentID)
+	done(err)
+	return comment, err
+}
+
+func (s *instrumentedCommentStore) GetByPostID(ctx context.Context, postID int64, cq CommentQuery) ([]Comment, Page, error) {
+	ctx, done := s.hook(ctx, "Comments.GetByPostID")
+	comments, page, err := s.next.Comments.GetByPostID(ctx, postID, cq)
+	done(err)
+	return comments, page, err
+}
+
+func (s *instrumentedCommentStore) Delete(ctx context.Context, commentID int64) error {
+	ctx, done := s.hook(ctx, "Comments.Delete")
+	err := s.next.Comments.Delete(ctx, commentID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedCommentStore) GetTrashed(ctx context.Context, commentID int64) (*Comment, error) {
+	ctx, done := s.hook(ctx, "Comments.GetTrashed")
+	comment, err := s.next.Comments.GetTrashed(ctx, commentID)
+	done(err)
+	return comment, err
+}
+
+func (s *instrumentedCommentStore) Restore(ctx context.Context, commentID int64, deletedSince time.Time) error {
+	ctx, done := s.hook(ctx, "Comments.Restore")
+	err := s.next.Comments.Restore(ctx, commentID, deletedSince)
+	done(err)
+	return err
+}
+
+func (s *instrumentedCommentStore) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	ctx, done := s.hook(ctx, "Comments.Purge")
+	n, err := s.next.Comments.Purge(ctx, deletedBefore)
+	done(err)
+	return n, err
+}
+
+type instrumentedFollowerStore struct {
+	next Storage
+	hook Hook
+}
+
+func (s *instrumentedFollowerStore) GetFollowers(ctx context.Context, userID int64, fq FollowQuery) ([]Follow, Page, error) {
+	ctx, done := s.hook(ctx, "Followers.GetFollowers")
+	follows, page, err := s.next.Followers.GetFollowers(ctx, userID, fq)
+	done(err)
+	return follows, page, err
+}
+
+func (s *instrumentedFollowerStore) GetFollowing(ctx context.Context, userID int64, fq FollowQuery) ([]Follow, Page, error) {
+	ctx, done := s.hook(ctx, "Followers.GetFollowing")
+	follows, page, err := s.next.Followers.GetFollowing(ctx, userID, fq)
+	done(err)
+	return follows, page, err
+}
+
+func (s *instrumentedFollowerStore) GetMutuals(ctx context.Context, userID int64, fq FollowQuery) ([]Follow, Page, error) {
+	ctx, done := s.hook(ctx, "Followers.GetMutuals")
+	follows, page, err := s.next.Followers.GetMutuals(ctx, userID, fq)
+	done(err)
+	return follows, page, err
+}
+
+func (s *instrumentedFollowerStore) GetStats(ctx context.Context, userID int64) (*FollowStats, error) {
+	ctx, done := s.hook(ctx, "Followers.GetStats")
+	stats, err := s.next.Followers.GetStats(ctx, userID)
+	done(err)
+	return stats, err
+}
+
+func (s *instrumentedFollowerStore) GetRelationship(ctx context.Context, userID, viewerID int64) (*Relationship, error) {
+	ctx, done := s.hook(ctx, "Followers.GetRelationship")
+	rel, err := s.next.Followers.GetRelationship(ctx, userID, viewerID)
+	done(err)
+	return rel, err
+}
+
+type instrumentedFollowRequestStore struct {
+	next Storage
+	hook Hook
+}
+
+func (s *instrumentedFollowRequestStore) Create(ctx context.Context, fr *FollowRequest) error {
+	ctx, done := s.hook(ctx, "FollowRequests.Create")
+	err := s.next.FollowRequests.Create(ctx, fr)
+	done(err)
+	return err
+}
+
+func (s *instrumentedFollowRequestStore) GetPending(ctx context.Context, targetID int64, fq FollowQuery) ([]FollowRequest, Page, error) {
+	ctx, done := s.hook(ctx, "FollowRequests.GetPending")
+	requests, page, err := s.next.FollowRequests.GetPending(ctx, targetID, fq)
+	done(err)
+	return requests, page, err
+}
+
+func (s *instrumentedFollowRequestStore) Approve(ctx context.Context, targetID, requesterID int64) error {
+	ctx, done := s.hook(ctx, "FollowRequests.Approve")
+	err := s.next.FollowRequests.Approve(ctx, targetID, requesterID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedFollowRequestStore) Reject(ctx context.Context, targetID, requesterID int64) error {
+	ctx, done := s.hook(ctx, "FollowRequests.Reject")
+	err := s.next.FollowRequests.Reject(ctx, targetID, requesterID)
+	done(err)
+	return err
+}
+
+type instrumentedBlockStore struct {
+	next Storage
+	hook Hook
+}
+
+func (s *instrumentedBlockStore) Block(ctx context.Context, blockerID, blockedID int64) error {
+	ctx, done := s.hook(ctx, "Blocks.Block")
+	err := s.next.Blocks.Block(ctx, blockerID, blockedID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedBlockStore) Unblock(ctx context.Context, blockerID, blockedID int64) error {
+	ctx, done := s.hook(ctx, "Blocks.Unblock")
+	err := s.next.Blocks.Unblock(ctx, blockerID, blockedID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedBlockStore) Mute(ctx context.Context, muterID, mutedID int64) error {
+	ctx, done := s.hook(ctx, "Blocks.Mute")
+	err := s.next.Blocks.Mute(ctx, muterID, mutedID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedBlockStore) Unmute(ctx context.Context, muterID, mutedID int64) error {
+	ctx, done := s.hook(ctx, "Blocks.Unmute")
+	err := s.next.Blocks.Unmute(ctx, muterID, mutedID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedBlockStore) IsBlocked(ctx context.Context, userID, otherID int64) (bool, error) {
+	ctx, done := s.hook(ctx, "Blocks.IsBlocked")
+	blocked, err := s.next.Blocks.IsBlocked(ctx, userID, otherID)
+	done(err)
+	return blocked, err
+}
+
+type instrumentedRevisionStore struct {
+	next Storage
+	hook Hook
+}
+
+func (s *instrumentedRevisionStore) GetByPostID(ctx context.Context, postID int64, rq RevisionQuery) ([]PostRevision, Page, error) {
+	ctx, done := s.hook(ctx, "Revisions.GetByPostID")
+	revisions, page, err := s.next.Revisions.GetByPostID(ctx, postID, rq)
+	done(err)
+	return revisions, page, err
+}
+
+func (s *instrumentedRevisionStore) GetByVersion(ctx context.Context, postID int64, version int) (*PostRevision, error) {
+	ctx, done := s.hook(ctx, "Revisions.GetByVersion")
+	revision, err := s.next.Revisions.GetByVersion(ctx, postID, version)
+	done(err)
+	return revision, err
+}
+
+type instrumentedReactionStore struct {
+	next Storage
+	hook Hook
+}
+
+func (s *instrumentedReactionStore) Add(ctx context.Context, reaction *Reaction) error {
+	ctx, done := s.hook(ctx, "Reactions.Add")
+	err := s.next.Reactions.Add(ctx, reaction)
+	done(err)
+	return err
+}
+
+func (s *instrumentedReactionStore) Remove(ctx context.Context, postID, userID int64, kind string) error {
+	ctx, done := s.hook(ctx, "Reactions.Remove")
+	err := s.next.Reactions.Remove(ctx, postID, userID, kind)
+	done(err)
+	return err
+}
+
+func (s *instrumentedReactionStore) GetCounts(ctx context.Context, postIDs []int64, userID int64) (map[int64][]ReactionCount, error) {
+	ctx, done := s.hook(ctx, "Reactions.GetCounts")
+	counts, err := s.next.Reactions.GetCounts(ctx, postIDs, userID)
+	done(err)
+	return counts, err
+}
+
+func (s *instrumentedReactionStore) GetByPostID(ctx context.Context, postID int64, kind string, rq ReactionQuery) ([]Reaction, Page, error) {
+	ctx, done := s.hook(ctx, "Reactions.GetByPostID")
+	reactions, page, err := s.next.Reactions.GetByPostID(ctx, postID, kind, rq)
+	done(err)
+	return reactions, page, err
+}
+
+type instrumentedSearchStore struct {
+	next Storage
+	hook Hook
+}
+
+func (s *instrumentedSearchStore) Posts(ctx context.Context, sq SearchQuery) ([]PostSearchResult, Page, error) {
+	ctx, done := s.hook(ctx, "Search.Posts")
+	results, page, err := s.next.Search.Posts(ctx, sq)
+	done(err)
+	return results, page, err
+}
+
+func (s *instrumentedSearchStore) Users(ctx context.Context, sq SearchQuery) ([]UserSearchResult, Page, error) {
+	ctx, done := s.hook(ctx, "Search.Users")
+	results, page, err := s.next.Search.Users(ctx, sq)
+	done(err)
+	return results, page, err
+}
+
+func (s *instrumentedSearchStore) Comments(ctx context.Context, sq SearchQuery) ([]CommentSearchResult, Page, error) {
+	ctx, done := s.hook(ctx, "Search.Comments")
+	results, page, err := s.next.Search.Comments(ctx, sq)
+	done(err)
+	return results, page, err
+}
+
+type instrumentedRoleStore struct {
+	next Storage
+	hook Hook
+}
+
+func (s *instrumentedRoleStore) GetByName(ctx context.Context, name string) (*Role, error) {
+	ctx, done := s.hook(ctx, "Roles.GetByName")
+	role, err := s.next.Roles.GetByName(ctx, name)
+	done(err)
+	return role, err
+}
+
+type instrumentedSessionStore struct {
+	next Storage
+	hook Hook
+}
+
+func (s *instrumentedSessionStore) Create(ctx context.Context, session *Session) error {
+	ctx, done := s.hook(ctx, "Sessions.Create")
+	err := s.next.Sessions.Create(ctx, session)
+	done(err)
+	return err
+}
+
+func (s *instrumentedSessionStore) GetByToken(ctx context.Context, token string) (*Session, error) {
+	ctx, done := s.hook(ctx, "Sessions.GetByToken")
+	session, err := s.next.Sessions.GetByToken(ctx, token)
+	done(err)
+	return session, err
+}
+
+func (s *instrumentedSessionStore) GetByUserID(ctx context.Context, userID int64) ([]Session, error) {
+	ctx, done := s.hook(ctx, "Sessions.GetByUserID")
+	sessions, err := s.next.Sessions.GetByUserID(ctx, userID)
+	done(err)
+	return sessions, err
+}
+
+func (s *instrumentedSessionStore) Rotate(ctx context.Context, current, next *Session) error {
+	ctx, done := s.hook(ctx, "Sessions.Rotate")
+	err := s.next.Sessions.Rotate(ctx, current, next)
+	done(err)
+	return err
+}
+
+func (s *instrumentedSessionStore) GetActive(ctx context.Context, userID int64) (*ActiveSessions, error) {
+	ctx, done := s.hook(ctx, "Sessions.GetActive")
+	active, err := s.next.Sessions.GetActive(ctx, userID)
+	done(err)
+	return active, err
+}
+
+func (s *instrumentedSessionStore) RevokeFamily(ctx context.Context, userID int64, familyID string) error {
+	ctx, done := s.hook(ctx, "Sessions.RevokeFamily")
+	err := s.next.Sessions.RevokeFamily(ctx, userID, familyID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedSessionStore) RevokeByID(ctx context.Context, userID, sessionID int64) error {
+	ctx, done := s.hook(ctx, "Sessions.RevokeByID")
+	err := s.next.Sessions.RevokeByID(ctx, userID, sessionID)
+	done(err)
+	return err
+}
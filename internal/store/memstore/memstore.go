@@ -0,0 +1,925 @@
+// Package memstore keeps a store.Storage in memory for tests, which import
+// it instead of running Postgres.
+package memstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+// New returns a Storage kept in memory, so handlers can be tested without
+// Postgres. It follows the Postgres stores closely: versioned updates,
+// expiring invitations and resets, cascading deletes and the same keyset
+// pagination. Full-text search is approximated by matching every word of
+// the query.
+func New() store.Storage {
+	db := &memoryDB{
+		users:          map[int64]store.User{},
+		posts:          map[int64]store.Post{},
+		comments:       map[int64]store.Comment{},
+		followers:      map[followKey]string{},
+		followRequests: map[followKey]store.FollowRequest{},
+		blocks:         map[userPair]bool{},
+		mutes:          map[userPair]bool{},
+		invitations:    map[string]tokenGrant{},
+		passwordResets: map[string]tokenGrant{},
+		sessions:       map[int64]store.Session{},
+		reactions:      map[reactionKey]store.Reaction{},
+		revisions:      map[revisionKey]store.PostRevision{},
+		roles: []store.Role{
+			{ID: 1, Name: "user", Level: 1, Description: "A user can create posts and comments"},
+			{ID: 2, Name: "moderator", Level: 2, Description: "A moderator can update other users posts and delete their comments"},
+			{ID: 3, Name: "admin", Level: 3, Description: "An admin can update and delete other users posts and comments"},
+		},
+	}
+
+	return store.Storage{
+		Posts:          &mockPostStore{db},
+		Users:          &mockUserStore{db},
+		Comments:       &mockCommentStore{db},
+		Followers:      &mockFollowerStore{db},
+		Blocks:         &mockBlockStore{db},
+		FollowRequests: &mockFollowRequestStore{db},
+		Revisions:      &mockRevisionStore{db},
+		Reactions:      &mockReactionStore{db},
+		Search:         &mockSearchStore{db},
+		Roles:          &mockRoleStore{db},
+		Sessions:       &mockSessionStore{db},
+	}
+}
+
+// memoryDB holds the tables behind the mock stores. Every store locks mu for
+// the whole call, which makes multi-step writes atomic like a transaction.
+type memoryDB struct {
+	mu     sync.Mutex
+	lastID int64
+
+	users          map[int64]store.User
+	posts          map[int64]store.Post
+	comments       map[int64]store.Comment
+	followers      map[followKey]string
+	followRequests map[followKey]store.FollowRequest
+	blocks         map[userPair]bool
+	mutes          map[userPair]bool
+	invitations    map[string]tokenGrant
+	passwordResets map[string]tokenGrant
+	sessions       map[int64]store.Session
+	reactions      map[reactionKey]store.Reaction
+	revisions      map[revisionKey]store.PostRevision
+	roles          []store.Role
+}
+
+// followKey mirrors a row of the followers table, which is mapped to its
+// created_at.
+type followKey struct {
+	userID     int64
+	followerID int64
+}
+
+// userPair mirrors a row of the user_blocks or user_mutes table: userID
+// blocked or muted otherID.
+type userPair struct {
+	userID  int64
+	otherID int64
+}
+
+type reactionKey struct {
+	postID int64
+	userID int64
+	kind   string
+}
+
+type revisionKey struct {
+	postID  int64
+	version int
+}
+
+// tokenGrant is an invitation or password reset token.
+type tokenGrant struct {
+	userID int64
+	expiry time.Time
+}
+
+func (db *memoryDB) nextID() int64 {
+	db.lastID++
+	return db.lastID
+}
+
+// timestamp returns the current time the way Postgres hands back a
+// TIMESTAMP(0) WITH TIME ZONE column.
+func timestamp() string {
+	return time.Now().UTC().Truncate(time.Second).Format(time.RFC3339)
+}
+
+func hashMockToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func (db *memoryDB) role(id int64) (store.Role, bool) {
+	for _, r := range db.roles {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return store.Role{}, false
+}
+
+// blocked reports whether either user has blocked the other.
+func (db *memoryDB) blocked(userID, otherID int64) bool {
+	return db.blocks[userPair{userID, otherID}] || db.blocks[userPair{otherID, userID}]
+}
+
+// hiddenFrom reports whether the posts of authorID are hidden from
+// viewerID, because of a block or because the author is private and
+// viewerID doesn't follow them.
+func (db *memoryDB) hiddenFrom(viewerID, authorID int64) bool {
+	if viewerID == authorID {
+		return false
+	}
+	if db.blocked(viewerID, authorID) {
+		return true
+	}
+	_, following := db.followers[followKey{userID: viewerID, followerID: authorID}]
+	return db.users[authorID].IsPrivate && !following
+}
+
+// visible reports whether the visibility of post lets viewerID see it.
+func (db *memoryDB) visible(viewerID int64, post store.Post) bool {
+	if viewerID == post.UserID {
+		return true
+	}
+	switch post.Visibility {
+	case store.PostFollowers:
+		_, following := db.followers[followKey{userID: viewerID, followerID: post.UserID}]
+		return following
+	case store.PostMentioned:
+		return slices.Contains(post.MentionedIDs, viewerID)
+	default:
+		return true
+	}
+}
+
+// userIDs returns the IDs of the users named by usernames, like the
+// mentioned_ids subquery of the Postgres store.
+func (db *memoryDB) userIDs(usernames []string) []int64 {
+	ids := []int64{}
+	for _, u := range db.users {
+		if slices.Contains(usernames, u.Username) {
+			ids = append(ids, u.ID)
+		}
+	}
+	return ids
+}
+
+func (db *memoryDB) username(userID int64) string {
+	return db.users[userID].Username
+}
+
+type mockUserStore struct {
+	db *memoryDB
+}
+
+func (s *mockUserStore) Create(ctx context.Context, tx *sql.Tx, user *store.User) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.create(user)
+}
+
+func (s *mockUserStore) create(user *store.User) error {
+	for _, u := range s.db.users {
+		if u.Username == user.Username {
+			return fmt.Errorf("duplicate key value violates unique constraint %q", "users_username_key")
+		}
+		if strings.EqualFold(u.Email, user.Email) {
+			return fmt.Errorf("duplicate key value violates unique constraint %q", "users_email_key")
+		}
+	}
+
+	role := user.Role.Name
+	if role == "" {
+		role = "user"
+	}
+	var roleID int64
+	for _, r := range s.db.roles {
+		if r.Name == role {
+			roleID = r.ID
+		}
+	}
+	if roleID == 0 {
+		return fmt.Errorf("null value in column %q violates not-null constraint", "role_id")
+	}
+
+	user.ID = s.db.nextID()
+	user.CreatedAt = timestamp()
+	user.RoleID = roleID
+
+	// users start out inactive until they accept their invitation
+	stored := *user
+	stored.IsActive = false
+	stored.Role = store.Role{}
+	s.db.users[user.ID] = stored
+
+	return nil
+}
+
+func (s *mockUserStore) GetByID(ctx context.Context, userID int64) (*store.User, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	user, ok := s.db.users[userID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return s.withRole(user), nil
+}
+
+func (s *mockUserStore) GetByEmail(ctx context.Context, email string) (*store.User, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, user := range s.db.users {
+		if strings.EqualFold(user.Email, email) && user.IsActive {
+			return s.withRole(user), nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s *mockUserStore) withRole(user store.User) *store.User {
+	user.Role, _ = s.db.role(user.RoleID)
+	return &user
+}
+
+func (s *mockUserStore) Follow(ctx context.Context, followerUserID, followingUserID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	_, okFollower := s.db.users[followerUserID]
+	_, okFollowing := s.db.users[followingUserID]
+	if !okFollower || !okFollowing {
+		return fmt.Errorf("insert or update on table %q violates foreign key constraint", "followers")
+	}
+
+	if s.db.blocked(followerUserID, followingUserID) {
+		return store.ErrBlocked
+	}
+
+	key := followKey{userID: followerUserID, followerID: followingUserID}
+	if _, ok := s.db.followers[key]; ok {
+		return store.ErrAlreadyFollowing
+	}
+	s.db.followers[key] = timestamp()
+
+	return nil
+}
+
+func (s *mockUserStore) UnFollow(ctx context.Context, followerUserID, followingUserID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	key := followKey{userID: followerUserID, followerID: followingUserID}
+	if _, ok := s.db.followers[key]; !ok {
+		return store.ErrNotFound
+	}
+	delete(s.db.followers, key)
+
+	return nil
+}
+
+func (s *mockUserStore) CreateAndInvite(ctx context.Context, user *store.User, token string, invitationExp time.Duration) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if err := s.create(user); err != nil {
+		return err
+	}
+	s.db.invitations[token] = tokenGrant{userID: user.ID, expiry: time.Now().Add(invitationExp)}
+
+	return nil
+}
+
+func (s *mockUserStore) Activate(ctx context.Context, token string) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	invitation, ok := s.db.invitations[hashMockToken(token)]
+	if !ok || !invitation.expiry.After(time.Now()) {
+		return 0, store.ErrNotFound
+	}
+
+	user, ok := s.db.users[invitation.userID]
+	if !ok {
+		return 0, store.ErrNotFound
+	}
+	user.IsActive = true
+	s.db.users[user.ID] = user
+
+	deleteGrants(s.db.invitations, user.ID)
+
+	return user.ID, nil
+}
+
+func (s *mockUserStore) Delete(ctx context.Context, userID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[userID]; !ok {
+		return store.ErrNotFound
+	}
+	for _, post := range s.db.posts {
+		if post.UserID == userID {
+			return fmt.Errorf("update or delete on table %q violates foreign key constraint %q", "users", "fk_user")
+		}
+	}
+
+	delete(s.db.users, userID)
+
+	// the tables below reference users with ON DELETE CASCADE
+	deleteGrants(s.db.invitations, userID)
+	deleteGrants(s.db.passwordResets, userID)
+	for key := range s.db.followers {
+		if key.userID == userID || key.followerID == userID {
+			delete(s.db.followers, key)
+		}
+	}
+	for id, session := range s.db.sessions {
+		if session.UserID == userID {
+			delete(s.db.sessions, id)
+		}
+	}
+	for key := range s.db.followRequests {
+		if key.userID == userID || key.followerID == userID {
+			delete(s.db.followRequests, key)
+		}
+	}
+	for _, pairs := range []map[userPair]bool{s.db.blocks, s.db.mutes} {
+		for key := range pairs {
+			if key.userID == userID || key.otherID == userID {
+				delete(pairs, key)
+			}
+		}
+	}
+	for key := range s.db.reactions {
+		if key.userID == userID {
+			delete(s.db.reactions, key)
+		}
+	}
+
+	return nil
+}
+
+func (s *mockUserStore) CreatePasswordReset(ctx context.Context, userID int64, token string, exp time.Duration) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[userID]; !ok {
+		return fmt.Errorf("insert or update on table %q violates foreign key constraint", "password_resets")
+	}
+	s.db.passwordResets[token] = tokenGrant{userID: userID, expiry: time.Now().Add(exp)}
+
+	return nil
+}
+
+func (s *mockUserStore) ResetPassword(ctx context.Context, token string, password store.Password) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	reset, ok := s.db.passwordResets[hashMockToken(token)]
+	if !ok || !reset.expiry.After(time.Now()) {
+		return 0, store.ErrNotFound
+	}
+
+	user, ok := s.db.users[reset.userID]
+	if !ok {
+		return 0, store.ErrNotFound
+	}
+	user.Password = password
+	s.db.users[user.ID] = user
+
+	deleteGrants(s.db.passwordResets, user.ID)
+	revokeMockSessions(s.db, func(session store.Session) bool {
+		return session.UserID == user.ID
+	})
+
+	return user.ID, nil
+}
+
+func (s *mockUserStore) SetPrivate(ctx context.Context, userID int64, private bool) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	user, ok := s.db.users[userID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	user.IsPrivate = private
+	s.db.users[userID] = user
+
+	if !private {
+		s.db.approveFollowRequests(userID, nil)
+	}
+
+	return nil
+}
+
+func (s *mockUserStore) UpdateProfile(ctx context.Context, user *store.User, changedBefore time.Time) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	stored, ok := s.db.users[user.ID]
+	if !ok {
+		return store.ErrNotFound
+	}
+
+	if stored.Username != user.Username {
+		for _, u := range s.db.users {
+			if u.Username == user.Username {
+				return store.ErrUsernameTaken
+			}
+		}
+		changedEarlier, err := deletedBefore(stored.UsernameChangedAt, changedBefore)
+		if err != nil {
+			return err
+		}
+		if stored.UsernameChangedAt != nil && !changedEarlier {
+			return store.ErrUsernameCooldown
+		}
+		changedAt := timestamp()
+		stored.Username = user.Username
+		stored.UsernameChangedAt = &changedAt
+	}
+
+	stored.DisplayName = user.DisplayName
+	stored.Bio = user.Bio
+	stored.Website = user.Website
+	stored.Location = user.Location
+	stored.AvatarURL = user.AvatarURL
+	s.db.users[user.ID] = stored
+
+	user.UsernameChangedAt = stored.UsernameChangedAt
+
+	return nil
+}
+
+func deleteGrants(grants map[string]tokenGrant, userID int64) {
+	for token, grant := range grants {
+		if grant.userID == userID {
+			delete(grants, token)
+		}
+	}
+}
+
+type mockRoleStore struct {
+	db *memoryDB
+}
+
+func (s *mockRoleStore) GetByName(ctx context.Context, name string) (*store.Role, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, role := range s.db.roles {
+		if role.Name == name {
+			return &role, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+type mockSessionStore struct {
+	db *memoryDB
+}
+
+func (s *mockSessionStore) Create(ctx context.Context, session *store.Session) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.create(session)
+}
+
+func (s *mockSessionStore) create(session *store.Session) error {
+	if _, ok := s.db.users[session.UserID]; !ok {
+		return fmt.Errorf("insert or update on table %q violates foreign key constraint", "sessions")
+	}
+	for _, existing := range s.db.sessions {
+		if existing.Token == session.Token {
+			return fmt.Errorf("duplicate key value violates unique constraint %q", "sessions_token_key")
+		}
+	}
+
+	session.ID = s.db.nextID()
+	session.CreatedAt = timestamp()
+	s.db.sessions[session.ID] = *session
+
+	return nil
+}
+
+func (s *mockSessionStore) GetByToken(ctx context.Context, token string) (*store.Session, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, session := range s.db.sessions {
+		if session.Token == token {
+			return &session, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s *mockSessionStore) GetByUserID(ctx context.Context, userID int64) ([]store.Session, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	now := time.Now()
+	sessions := []store.Session{}
+	for _, session := range s.db.sessions {
+		if session.UserID == userID && session.RevokedAt == nil && session.Expiry.After(now) {
+			session.Token = ""
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].CreatedAt != sessions[j].CreatedAt {
+			return sessions[i].CreatedAt > sessions[j].CreatedAt
+		}
+		return sessions[i].ID > sessions[j].ID
+	})
+
+	return sessions, nil
+}
+
+func (s *mockSessionStore) Rotate(ctx context.Context, current, next *store.Session) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	session, ok := s.db.sessions[current.ID]
+	if !ok || session.RevokedAt != nil {
+		return store.ErrNotFound
+	}
+
+	next.FamilyID = current.FamilyID
+	if err := s.create(next); err != nil {
+		return err
+	}
+
+	revokedAt := timestamp()
+	session.RevokedAt = &revokedAt
+	s.db.sessions[session.ID] = session
+
+	return nil
+}
+
+func (s *mockSessionStore) GetActive(ctx context.Context, userID int64) (*store.ActiveSessions, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	now := time.Now()
+	expiries := map[string]time.Time{}
+	active := &store.ActiveSessions{UserID: userID, FamilyIDs: []string{}}
+	for _, session := range s.db.sessions {
+		if session.UserID != userID || session.RevokedAt != nil || !session.Expiry.After(now) {
+			continue
+		}
+		if !active.Contains(session.FamilyID) {
+			active.FamilyIDs = append(active.FamilyIDs, session.FamilyID)
+		}
+		if session.Expiry.After(expiries[session.FamilyID]) {
+			expiries[session.FamilyID] = session.Expiry
+		}
+	}
+	for _, expiry := range expiries {
+		if active.Expiry.IsZero() || expiry.Before(active.Expiry) {
+			active.Expiry = expiry
+		}
+	}
+
+	return active, nil
+}
+
+func (s *mockSessionStore) RevokeFamily(ctx context.Context, userID int64, familyID string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	revokeMockSessions(s.db, func(session store.Session) bool {
+		return session.UserID == userID && session.FamilyID == familyID
+	})
+
+	return nil
+}
+
+func (s *mockSessionStore) RevokeByID(ctx context.Context, userID, sessionID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	session, ok := s.db.sessions[sessionID]
+	if !ok || session.UserID != userID {
+		return store.ErrNotFound
+	}
+
+	revoked := revokeMockSessions(s.db, func(other store.Session) bool {
+		return other.FamilyID == session.FamilyID
+	})
+	if revoked == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+// revokeMockSessions revokes the live sessions matching match and returns
+// how many there were.
+func revokeMockSessions(db *memoryDB, match func(store.Session) bool) int {
+	revoked := 0
+	revokedAt := timestamp()
+	for id, session := range db.sessions {
+		if session.RevokedAt == nil && match(session) {
+			session.RevokedAt = &revokedAt
+			db.sessions[id] = session
+			revoked++
+		}
+	}
+	return revoked
+}
+
+type mockFollowerStore struct {
+	db *memoryDB
+}
+
+func (s *mockFollowerStore) GetFollowers(ctx context.Context, userID int64, fq store.FollowQuery) ([]store.Follow, store.Page, error) {
+	return s.list(fq, func(key followKey) (int64, bool) {
+		return key.userID, key.followerID == userID
+	})
+}
+
+func (s *mockFollowerStore) GetFollowing(ctx context.Context, userID int64, fq store.FollowQuery) ([]store.Follow, store.Page, error) {
+	return s.list(fq, func(key followKey) (int64, bool) {
+		return key.followerID, key.userID == userID
+	})
+}
+
+func (s *mockFollowerStore) GetMutuals(ctx context.Context, userID int64, fq store.FollowQuery) ([]store.Follow, store.Page, error) {
+	return s.list(fq, func(key followKey) (int64, bool) {
+		_, back := s.db.followers[followKey{userID: key.followerID, followerID: key.userID}]
+		return key.followerID, key.userID == userID && back
+	})
+}
+
+// list pages through the follows selected by match, which returns the other
+// user of a row and whether the row belongs to the list.
+func (s *mockFollowerStore) list(fq store.FollowQuery, match func(followKey) (int64, bool)) ([]store.Follow, store.Page, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	follows := []store.Follow{}
+	for key, createdAt := range s.db.followers {
+		otherID, ok := match(key)
+		if !ok || s.db.blocked(fq.ViewerID, otherID) {
+			continue
+		}
+		follows = append(follows, store.Follow{
+			User:       store.User{ID: otherID, Username: s.db.username(otherID)},
+			FollowedAt: createdAt,
+		})
+	}
+
+	feed := fq.FeedQuery()
+	follows, err := mockKeyset(follows, feed, store.FollowPosition)
+	if err != nil {
+		return nil, store.Page{}, err
+	}
+
+	return store.Paginate(follows, feed, store.FollowPosition)
+}
+
+func (s *mockFollowerStore) GetStats(ctx context.Context, userID int64) (*store.FollowStats, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	var stats store.FollowStats
+	for key := range s.db.followers {
+		if key.followerID == userID {
+			stats.Followers++
+		}
+		if key.userID == userID {
+			stats.Following++
+		}
+	}
+	return &stats, nil
+}
+
+func (s *mockFollowerStore) GetRelationship(ctx context.Context, userID, viewerID int64) (*store.Relationship, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	_, isFollowing := s.db.followers[followKey{userID: viewerID, followerID: userID}]
+	_, followsYou := s.db.followers[followKey{userID: userID, followerID: viewerID}]
+
+	return &store.Relationship{IsFollowing: isFollowing, FollowsYou: followsYou}, nil
+}
+
+type mockBlockStore struct {
+	db *memoryDB
+}
+
+func (s *mockBlockStore) Block(ctx context.Context, blockerID, blockedID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if err := s.db.checkPair("user_blocks", blockerID, blockedID); err != nil {
+		return err
+	}
+	s.db.blocks[userPair{blockerID, blockedID}] = true
+
+	delete(s.db.followers, followKey{userID: blockerID, followerID: blockedID})
+	delete(s.db.followers, followKey{userID: blockedID, followerID: blockerID})
+
+	for _, key := range []followKey{
+		{userID: blockerID, followerID: blockedID},
+		{userID: blockedID, followerID: blockerID},
+	} {
+		if fr, ok := s.db.followRequests[key]; ok && fr.Status == store.FollowRequestPending {
+			fr.Status = store.FollowRequestRejected
+			fr.UpdatedAt = timestamp()
+			s.db.followRequests[key] = fr
+		}
+	}
+
+	return nil
+}
+
+func (s *mockBlockStore) Unblock(ctx context.Context, blockerID, blockedID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return removePair(s.db.blocks, blockerID, blockedID)
+}
+
+func (s *mockBlockStore) Mute(ctx context.Context, muterID, mutedID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if err := s.db.checkPair("user_mutes", muterID, mutedID); err != nil {
+		return err
+	}
+	s.db.mutes[userPair{muterID, mutedID}] = true
+
+	return nil
+}
+
+func (s *mockBlockStore) Unmute(ctx context.Context, muterID, mutedID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return removePair(s.db.mutes, muterID, mutedID)
+}
+
+func (s *mockBlockStore) IsBlocked(ctx context.Context, userID, otherID int64) (bool, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.db.blocked(userID, otherID), nil
+}
+
+// checkPair enforces the foreign keys and check constraint of the
+// user_blocks and user_mutes tables.
+func (db *memoryDB) checkPair(table string, userID, otherID int64) error {
+	_, okUser := db.users[userID]
+	_, okOther := db.users[otherID]
+	if !okUser || !okOther {
+		return fmt.Errorf("insert or update on table %q violates foreign key constraint", table)
+	}
+	if userID == otherID {
+		return fmt.Errorf("new row for relation %q violates check constraint", table)
+	}
+	return nil
+}
+
+func removePair(pairs map[userPair]bool, userID, otherID int64) error {
+	key := userPair{userID, otherID}
+	if !pairs[key] {
+		return store.ErrNotFound
+	}
+	delete(pairs, key)
+	return nil
+}
+
+// mockFollowRequestStore keys follow requests like followers: userID is the
+// requester and followerID the target.
+type mockFollowRequestStore struct {
+	db *memoryDB
+}
+
+func (s *mockFollowRequestStore) Create(ctx context.Context, fr *store.FollowRequest) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	_, okRequester := s.db.users[fr.RequesterID]
+	_, okTarget := s.db.users[fr.TargetID]
+	if !okRequester || !okTarget {
+		return fmt.Errorf("insert or update on table %q violates foreign key constraint", "follow_requests")
+	}
+	if s.db.blocked(fr.RequesterID, fr.TargetID) {
+		return store.ErrBlocked
+	}
+
+	key := followKey{userID: fr.RequesterID, followerID: fr.TargetID}
+	stored, ok := s.db.followRequests[key]
+	if !ok || stored.Status != store.FollowRequestPending {
+		stored = store.FollowRequest{
+			RequesterID: fr.RequesterID,
+			TargetID:    fr.TargetID,
+			CreatedAt:   timestamp(),
+		}
+	}
+	stored.Status = store.FollowRequestPending
+	stored.UpdatedAt = timestamp()
+	s.db.followRequests[key] = stored
+
+	fr.Status = stored.Status
+	fr.CreatedAt = stored.CreatedAt
+	fr.UpdatedAt = stored.UpdatedAt
+
+	return nil
+}
+
+func (s *mockFollowRequestStore) GetPending(ctx context.Context, targetID int64, fq store.FollowQuery) ([]store.FollowRequest, store.Page, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	requests := []store.FollowRequest{}
+	for _, fr := range s.db.followRequests {
+		if fr.TargetID == targetID && fr.Status == store.FollowRequestPending {
+			fr.Requester = store.User{ID: fr.RequesterID, Username: s.db.username(fr.RequesterID)}
+			requests = append(requests, fr)
+		}
+	}
+
+	feed := fq.FeedQuery()
+	requests, err := mockKeyset(requests, feed, store.FollowRequestPosition)
+	if err != nil {
+		return nil, store.Page{}, err
+	}
+
+	return store.Paginate(requests, feed, store.FollowRequestPosition)
+}
+
+func (s *mockFollowRequestStore) Approve(ctx context.Context, targetID, requesterID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if s.db.approveFollowRequests(targetID, &requesterID) == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s *mockFollowRequestStore) Reject(ctx context.Context, targetID, requesterID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	key := followKey{userID: requesterID, followerID: targetID}
+	fr, ok := s.db.followRequests[key]
+	if !ok || fr.Status != store.FollowRequestPending {
+		return store.ErrNotFound
+	}
+	fr.Status = store.FollowRequestRejected
+	fr.UpdatedAt = timestamp()
+	s.db.followRequests[key] = fr
+
+	return nil
+}
+
+// approveFollowRequests approves the pending requests to follow targetID,
+// only the one of requesterID unless it is nil, and returns how many were
+// approved.
+func (db *memoryDB) approveFollowRequests(targetID int64, requesterID *int64) int {
+	approved := 0
+	for key, fr := range db.followRequests {
+		if fr.TargetID != targetID || fr.Status != store.FollowRequestPending {
+			continue
+		}
+		if requesterID != nil && fr.RequesterID != *requesterID {
+			continue
+		}
+		fr.Status = store.FollowRequestApproved
+		fr.UpdatedAt = timestamp()
+		db.followRequests[key] = fr
+
+		if _, ok := db.followers[key]; !ok && !db.blocked(fr.RequesterID, targetID) {
+			db.followers[key] = timestamp()
+		}
+		approved++
+	}
+	return approved
+}
@@ -0,0 +1,857 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sharukh010/social/internal/store"
+)
+
+type mockPostStore struct {
+	db *memoryDB
+}
+
+func (s *mockPostStore) Create(ctx context.Context, post *store.Post) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[post.UserID]; !ok {
+		return fmt.Errorf("insert or update on table %q violates foreign key constraint %q", "posts", "fk_user")
+	}
+
+	post.ID = s.db.nextID()
+	post.CreatedAt = timestamp()
+	post.UpdatedAt = post.CreatedAt
+	if post.Visibility == "" {
+		post.Visibility = store.PostPublic
+	}
+	post.Mentions = store.MentionsIn(post.Title, post.Content)
+	post.MentionedIDs = s.db.userIDs(post.Mentions)
+
+	s.db.posts[post.ID] = store.Post{
+		ID:           post.ID,
+		Title:        post.Title,
+		Content:      post.Content,
+		UserID:       post.UserID,
+		Tags:         slices.Clone(post.Tags),
+		Visibility:   post.Visibility,
+		Mentions:     slices.Clone(post.Mentions),
+		MentionedIDs: slices.Clone(post.MentionedIDs),
+		CreatedAt:    post.CreatedAt,
+		UpdatedAt:    post.UpdatedAt,
+	}
+
+	return nil
+}
+
+func (s *mockPostStore) GetByID(ctx context.Context, postID int64) (*store.Post, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	post, ok := s.db.posts[postID]
+	if !ok || post.DeletedAt != nil {
+		return nil, store.ErrNotFound
+	}
+	post.Tags = slices.Clone(post.Tags)
+	post.Mentions = slices.Clone(post.Mentions)
+	post.MentionedIDs = slices.Clone(post.MentionedIDs)
+
+	return &post, nil
+}
+
+func (s *mockPostStore) Delete(ctx context.Context, postID int64, version int) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	post, ok := s.db.posts[postID]
+	if !ok || post.DeletedAt != nil {
+		return store.ErrNotFound
+	}
+	if post.Version != version {
+		return store.ErrConflict
+	}
+	deletedAt := timestamp()
+	post.DeletedAt = &deletedAt
+	s.db.posts[postID] = post
+
+	return nil
+}
+
+func (s *mockPostStore) GetTrashed(ctx context.Context, postID int64) (*store.Post, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	post, ok := s.db.posts[postID]
+	if !ok || post.DeletedAt == nil {
+		return nil, store.ErrNotFound
+	}
+	post.Tags = slices.Clone(post.Tags)
+	post.Mentions = slices.Clone(post.Mentions)
+	post.MentionedIDs = slices.Clone(post.MentionedIDs)
+
+	return &post, nil
+}
+
+func (s *mockPostStore) Restore(ctx context.Context, postID int64, deletedSince time.Time) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	post, ok := s.db.posts[postID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	deleted, err := deletedBefore(post.DeletedAt, deletedSince)
+	if err != nil {
+		return err
+	}
+	if post.DeletedAt == nil || deleted {
+		return store.ErrNotFound
+	}
+	post.DeletedAt = nil
+	s.db.posts[postID] = post
+
+	return nil
+}
+
+// Purge removes the posts trashed before the given time along with the rows
+// referencing them, which cascade in Postgres.
+func (s *mockPostStore) Purge(ctx context.Context, before time.Time) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	var n int64
+	for id, post := range s.db.posts {
+		deleted, err := deletedBefore(post.DeletedAt, before)
+		if err != nil {
+			return n, err
+		}
+		if !deleted {
+			continue
+		}
+		delete(s.db.posts, id)
+		n++
+
+		for commentID, c := range s.db.comments {
+			if c.PostID == id {
+				delete(s.db.comments, commentID)
+			}
+		}
+		for key := range s.db.reactions {
+			if key.postID == id {
+				delete(s.db.reactions, key)
+			}
+		}
+		for key := range s.db.revisions {
+			if key.postID == id {
+				delete(s.db.revisions, key)
+			}
+		}
+	}
+
+	return n, nil
+}
+
+// Update only applies when post.Version is still the stored version, and
+// reports ErrConflict otherwise, like the Postgres store.
+func (s *mockPostStore) Update(ctx context.Context, post *store.Post) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	stored, ok := s.db.posts[post.ID]
+	if !ok || stored.DeletedAt != nil {
+		return store.ErrNotFound
+	}
+	if stored.Version != post.Version {
+		return store.ErrConflict
+	}
+
+	s.db.revisions[revisionKey{post.ID, stored.Version}] = store.PostRevision{
+		PostID:    stored.ID,
+		Version:   stored.Version,
+		Title:     stored.Title,
+		Content:   stored.Content,
+		Tags:      slices.Clone(stored.Tags),
+		CreatedAt: stored.UpdatedAt,
+	}
+
+	stored.Title = post.Title
+	stored.Content = post.Content
+	stored.Tags = slices.Clone(post.Tags)
+	stored.Visibility = post.Visibility
+	stored.Mentions = store.MentionsIn(post.Title, post.Content)
+	stored.MentionedIDs = s.db.userIDs(stored.Mentions)
+	stored.UpdatedAt = timestamp()
+	stored.Version++
+	s.db.posts[post.ID] = stored
+
+	post.Version = stored.Version
+	post.Mentions = slices.Clone(stored.Mentions)
+	post.MentionedIDs = slices.Clone(stored.MentionedIDs)
+
+	return nil
+}
+
+func (s *mockPostStore) GetUserFeed(ctx context.Context, userID int64, fq store.PaginatedFeedQuery) ([]store.PostWithMetadata, store.Page, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	var since, until time.Time
+	if fq.Since != "" && fq.Until != "" {
+		var err error
+		if since, err = time.Parse(time.DateTime, fq.Since); err != nil {
+			return nil, store.Page{}, err
+		}
+		if until, err = time.Parse(time.DateTime, fq.Until); err != nil {
+			return nil, store.Page{}, err
+		}
+	}
+
+	feed := []store.PostWithMetadata{}
+	for _, post := range s.db.posts {
+		if post.DeletedAt != nil || s.db.mutes[userPair{userID, post.UserID}] || s.db.blocked(userID, post.UserID) {
+			continue
+		}
+		_, following := s.db.followers[followKey{userID: userID, followerID: post.UserID}]
+		if post.UserID != userID && !following {
+			continue
+		}
+		if !s.db.visible(userID, post) {
+			continue
+		}
+		if fq.Search != "" {
+			if _, ok := matchWords(fq.Search, post.Title, post.Content); !ok {
+				continue
+			}
+		}
+		if !containsAll(post.Tags, fq.Tags) {
+			continue
+		}
+		if !since.IsZero() {
+			createdAt, err := time.Parse(time.RFC3339, post.CreatedAt)
+			if err != nil {
+				return nil, store.Page{}, err
+			}
+			if createdAt.Before(since) || createdAt.After(until) {
+				continue
+			}
+		}
+
+		post.Tags = slices.Clone(post.Tags)
+		post.Mentions = slices.Clone(post.Mentions)
+		post.User.Username = s.db.username(post.UserID)
+		item := store.PostWithMetadata{Post: post}
+		for _, c := range s.db.comments {
+			if c.PostID == post.ID && c.DeletedAt == nil {
+				item.CommentCount++
+			}
+		}
+		feed = append(feed, item)
+	}
+
+	feed, err := mockKeyset(feed, fq, func(p store.PostWithMetadata) (store.Cursor, error) {
+		return store.PostCursor(p.Post)
+	})
+	if err != nil {
+		return nil, store.Page{}, err
+	}
+
+	feed, page, err := store.Paginate(feed, fq, func(p store.PostWithMetadata) (store.Cursor, error) {
+		return store.PostCursor(p.Post)
+	})
+	if err != nil {
+		return nil, store.Page{}, err
+	}
+
+	postIDs := make([]int64, len(feed))
+	for i, post := range feed {
+		postIDs[i] = post.ID
+	}
+	counts := mockReactionCounts(s.db, postIDs, userID)
+	for i := range feed {
+		feed[i].Reactions = counts[feed[i].ID]
+	}
+
+	return feed, page, nil
+}
+
+type mockCommentStore struct {
+	db *memoryDB
+}
+
+func (s *mockCommentStore) Create(ctx context.Context, comment *store.Comment) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.posts[comment.PostID]; !ok {
+		return fmt.Errorf("insert or update on table %q violates foreign key constraint %q", "comments", "fk_comments_post")
+	}
+	if comment.ParentID != nil {
+		if _, ok := s.db.comments[*comment.ParentID]; !ok {
+			return fmt.Errorf("insert or update on table %q violates foreign key constraint", "comments")
+		}
+	}
+
+	if !s.db.visible(comment.UserID, s.db.posts[comment.PostID]) {
+		return store.ErrNotFound
+	}
+	if s.db.blocked(comment.UserID, s.db.posts[comment.PostID].UserID) {
+		return store.ErrBlocked
+	}
+	if comment.ParentID != nil && s.db.blocked(comment.UserID, s.db.comments[*comment.ParentID].UserID) {
+		return store.ErrBlocked
+	}
+
+	comment.ID = s.db.nextID()
+	comment.CreatedAt = timestamp()
+	comment.User.ID = comment.UserID
+
+	s.db.comments[comment.ID] = store.Comment{
+		ID:        comment.ID,
+		PostID:    comment.PostID,
+		UserID:    comment.UserID,
+		ParentID:  comment.ParentID,
+		Depth:     comment.Depth,
+		Content:   comment.Content,
+		CreatedAt: comment.CreatedAt,
+	}
+
+	return nil
+}
+
+func (s *mockCommentStore) GetByID(ctx context.Context, commentID int64) (*store.Comment, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	c, ok := s.db.comments[commentID]
+	if !ok || c.DeletedAt != nil {
+		return nil, store.ErrNotFound
+	}
+	c = s.withMetadata(c, 0)
+
+	return &c, nil
+}
+
+func (s *mockCommentStore) GetByPostID(ctx context.Context, postID int64, cq store.CommentQuery) ([]store.Comment, store.Page, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	comments := []store.Comment{}
+	for _, c := range s.db.comments {
+		if c.PostID != postID || c.DeletedAt != nil || !sameParent(c.ParentID, cq.ParentID) || s.db.blocked(cq.ViewerID, c.UserID) {
+			continue
+		}
+		comments = append(comments, s.withMetadata(c, cq.ViewerID))
+	}
+
+	fq := cq.FeedQuery()
+	comments, err := mockKeyset(comments, fq, store.CommentCursor)
+	if err != nil {
+		return nil, store.Page{}, err
+	}
+
+	comments, page, err := store.Paginate(comments, fq, store.CommentCursor)
+	if err != nil {
+		return nil, store.Page{}, err
+	}
+
+	if err := store.NestReplies(ctx, comments, cq, s.getReplies); err != nil {
+		return nil, store.Page{}, err
+	}
+
+	return comments, page, nil
+}
+
+func (s *mockCommentStore) getReplies(ctx context.Context, parentIDs []int64, limit int, viewerID int64) (map[int64][]store.Comment, error) {
+	replies := map[int64][]store.Comment{}
+	for _, c := range s.db.comments {
+		if c.ParentID != nil && c.DeletedAt == nil && slices.Contains(parentIDs, *c.ParentID) && !s.db.blocked(viewerID, c.UserID) {
+			replies[*c.ParentID] = append(replies[*c.ParentID], s.withMetadata(c, viewerID))
+		}
+	}
+
+	for parentID, r := range replies {
+		sort.Slice(r, func(i, j int) bool {
+			if r[i].CreatedAt != r[j].CreatedAt {
+				return r[i].CreatedAt < r[j].CreatedAt
+			}
+			return r[i].ID < r[j].ID
+		})
+		if len(r) > limit {
+			replies[parentID] = r[:limit]
+		}
+	}
+
+	return replies, nil
+}
+
+func (s *mockCommentStore) Delete(ctx context.Context, commentID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	c, ok := s.db.comments[commentID]
+	if !ok || c.DeletedAt != nil {
+		return store.ErrNotFound
+	}
+	deletedAt := timestamp()
+	c.DeletedAt = &deletedAt
+	s.db.comments[commentID] = c
+
+	return nil
+}
+
+func (s *mockCommentStore) GetTrashed(ctx context.Context, commentID int64) (*store.Comment, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	c, ok := s.db.comments[commentID]
+	if !ok || c.DeletedAt == nil {
+		return nil, store.ErrNotFound
+	}
+	c.User.ID = c.UserID
+	c.User.Username = s.db.username(c.UserID)
+
+	return &c, nil
+}
+
+func (s *mockCommentStore) Restore(ctx context.Context, commentID int64, deletedSince time.Time) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	c, ok := s.db.comments[commentID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	deleted, err := deletedBefore(c.DeletedAt, deletedSince)
+	if err != nil {
+		return err
+	}
+	if c.DeletedAt == nil || deleted {
+		return store.ErrNotFound
+	}
+	c.DeletedAt = nil
+	s.db.comments[commentID] = c
+
+	return nil
+}
+
+// Purge removes the comments trashed before the given time along with all
+// of their replies.
+func (s *mockCommentStore) Purge(ctx context.Context, before time.Time) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	var n int64
+	for id, c := range s.db.comments {
+		deleted, err := deletedBefore(c.DeletedAt, before)
+		if err != nil {
+			return n, err
+		}
+		if deleted {
+			s.delete(id)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func (s *mockCommentStore) delete(commentID int64) {
+	delete(s.db.comments, commentID)
+	for id, c := range s.db.comments {
+		if c.ParentID != nil && *c.ParentID == commentID {
+			s.delete(id)
+		}
+	}
+}
+
+func (s *mockCommentStore) withMetadata(c store.Comment, viewerID int64) store.Comment {
+	c.User.ID = c.UserID
+	c.User.Username = s.db.username(c.UserID)
+	for _, r := range s.db.comments {
+		if r.ParentID != nil && *r.ParentID == c.ID && r.DeletedAt == nil && !s.db.blocked(viewerID, r.UserID) {
+			c.ReplyCount++
+		}
+	}
+	return c
+}
+
+// deletedBefore reports whether deletedAt is set and earlier than t.
+func deletedBefore(deletedAt *string, t time.Time) (bool, error) {
+	if deletedAt == nil {
+		return false, nil
+	}
+	at, err := time.Parse(time.RFC3339, *deletedAt)
+	if err != nil {
+		return false, err
+	}
+	return at.Before(t), nil
+}
+
+func sameParent(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+type mockReactionStore struct {
+	db *memoryDB
+}
+
+func (s *mockReactionStore) Add(ctx context.Context, reaction *store.Reaction) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	_, okPost := s.db.posts[reaction.PostID]
+	_, okUser := s.db.users[reaction.UserID]
+	if !okPost || !okUser {
+		return fmt.Errorf("insert or update on table %q violates foreign key constraint", "post_reactions")
+	}
+
+	key := reactionKey{postID: reaction.PostID, userID: reaction.UserID, kind: reaction.Kind}
+	stored, ok := s.db.reactions[key]
+	if !ok {
+		stored = store.Reaction{
+			PostID:    reaction.PostID,
+			UserID:    reaction.UserID,
+			Kind:      reaction.Kind,
+			CreatedAt: timestamp(),
+		}
+		s.db.reactions[key] = stored
+	}
+	reaction.CreatedAt = stored.CreatedAt
+
+	return nil
+}
+
+func (s *mockReactionStore) Remove(ctx context.Context, postID, userID int64, kind string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	key := reactionKey{postID: postID, userID: userID, kind: kind}
+	if _, ok := s.db.reactions[key]; !ok {
+		return store.ErrNotFound
+	}
+	delete(s.db.reactions, key)
+
+	return nil
+}
+
+func (s *mockReactionStore) GetCounts(ctx context.Context, postIDs []int64, userID int64) (map[int64][]store.ReactionCount, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return mockReactionCounts(s.db, postIDs, userID), nil
+}
+
+func (s *mockReactionStore) GetByPostID(ctx context.Context, postID int64, kind string, rq store.ReactionQuery) ([]store.Reaction, store.Page, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	reactions := []store.Reaction{}
+	for _, r := range s.db.reactions {
+		if r.PostID == postID && r.Kind == kind {
+			r.User.ID = r.UserID
+			r.User.Username = s.db.username(r.UserID)
+			reactions = append(reactions, r)
+		}
+	}
+
+	position := func(r store.Reaction) (store.Cursor, error) {
+		createdAt, err := time.Parse(time.RFC3339, r.CreatedAt)
+		if err != nil {
+			return store.Cursor{}, err
+		}
+		return store.Cursor{CreatedAt: createdAt, ID: r.UserID}, nil
+	}
+
+	fq := rq.FeedQuery()
+	reactions, err := mockKeyset(reactions, fq, position)
+	if err != nil {
+		return nil, store.Page{}, err
+	}
+
+	return store.Paginate(reactions, fq, position)
+}
+
+type mockRevisionStore struct {
+	db *memoryDB
+}
+
+func (s *mockRevisionStore) GetByPostID(ctx context.Context, postID int64, rq store.RevisionQuery) ([]store.PostRevision, store.Page, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	revisions := []store.PostRevision{}
+	for key, rev := range s.db.revisions {
+		if key.postID == postID {
+			rev.Tags = slices.Clone(rev.Tags)
+			revisions = append(revisions, rev)
+		}
+	}
+
+	fq := rq.FeedQuery()
+	revisions, err := mockKeyset(revisions, fq, store.RevisionPosition)
+	if err != nil {
+		return nil, store.Page{}, err
+	}
+
+	return store.Paginate(revisions, fq, store.RevisionPosition)
+}
+
+func (s *mockRevisionStore) GetByVersion(ctx context.Context, postID int64, version int) (*store.PostRevision, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rev, ok := s.db.revisions[revisionKey{postID, version}]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	rev.Tags = slices.Clone(rev.Tags)
+
+	return &rev, nil
+}
+
+func mockReactionCounts(db *memoryDB, postIDs []int64, userID int64) map[int64][]store.ReactionCount {
+	counts := map[int64][]store.ReactionCount{}
+	for _, postID := range postIDs {
+		byKind := map[string]*store.ReactionCount{}
+		for key := range db.reactions {
+			if key.postID != postID {
+				continue
+			}
+			rc, ok := byKind[key.kind]
+			if !ok {
+				rc = &store.ReactionCount{Kind: key.kind}
+				byKind[key.kind] = rc
+			}
+			rc.Count++
+			rc.ReactedByMe = rc.ReactedByMe || key.userID == userID
+		}
+
+		for _, rc := range byKind {
+			counts[postID] = append(counts[postID], *rc)
+		}
+		sort.Slice(counts[postID], func(i, j int) bool {
+			return counts[postID][i].Kind < counts[postID][j].Kind
+		})
+	}
+	return counts
+}
+
+type mockSearchStore struct {
+	db *memoryDB
+}
+
+func (s *mockSearchStore) Posts(ctx context.Context, sq store.SearchQuery) ([]store.PostSearchResult, store.Page, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	results := []store.PostSearchResult{}
+	for _, post := range s.db.posts {
+		if post.DeletedAt != nil || s.db.hiddenFrom(sq.ViewerID, post.UserID) || !s.db.visible(sq.ViewerID, post) {
+			continue
+		}
+		rank, ok := matchWords(sq.Query, post.Title, post.Content)
+		if !ok {
+			continue
+		}
+		post.Tags = slices.Clone(post.Tags)
+		post.Mentions = slices.Clone(post.Mentions)
+		post.User.ID = post.UserID
+		post.User.Username = s.db.username(post.UserID)
+		results = append(results, store.PostSearchResult{
+			Post:    post,
+			Rank:    rank,
+			Snippet: highlight(sq.Query, post.Content),
+		})
+	}
+
+	return searchPage(results, sq, func(r store.PostSearchResult) (store.Cursor, error) {
+		return store.Cursor{Rank: r.Rank, ID: r.ID}, nil
+	})
+}
+
+func (s *mockSearchStore) Users(ctx context.Context, sq store.SearchQuery) ([]store.UserSearchResult, store.Page, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	results := []store.UserSearchResult{}
+	for _, user := range s.db.users {
+		if !user.IsActive {
+			continue
+		}
+		rank, ok := matchWords(sq.Query, user.Username)
+		if !ok {
+			continue
+		}
+		results = append(results, store.UserSearchResult{
+			ID:       user.ID,
+			Username: user.Username,
+			Rank:     rank,
+			Snippet:  highlight(sq.Query, user.Username),
+		})
+	}
+
+	return searchPage(results, sq, func(r store.UserSearchResult) (store.Cursor, error) {
+		return store.Cursor{Rank: r.Rank, ID: r.ID}, nil
+	})
+}
+
+func (s *mockSearchStore) Comments(ctx context.Context, sq store.SearchQuery) ([]store.CommentSearchResult, store.Page, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	results := []store.CommentSearchResult{}
+	for _, c := range s.db.comments {
+		post := s.db.posts[c.PostID]
+		if c.DeletedAt != nil || post.DeletedAt != nil ||
+			s.db.blocked(sq.ViewerID, c.UserID) || s.db.hiddenFrom(sq.ViewerID, post.UserID) ||
+			!s.db.visible(sq.ViewerID, post) {
+			continue
+		}
+		rank, ok := matchWords(sq.Query, c.Content)
+		if !ok {
+			continue
+		}
+		c.User.ID = c.UserID
+		c.User.Username = s.db.username(c.UserID)
+		results = append(results, store.CommentSearchResult{
+			Comment: c,
+			Rank:    rank,
+			Snippet: highlight(sq.Query, c.Content),
+		})
+	}
+
+	return searchPage(results, sq, func(r store.CommentSearchResult) (store.Cursor, error) {
+		return store.Cursor{Rank: r.Rank, ID: r.ID}, nil
+	})
+}
+
+func searchPage[T any](results []T, sq store.SearchQuery, position func(T) (store.Cursor, error)) ([]T, store.Page, error) {
+	fq := sq.FeedQuery()
+	results, err := mockKeyset(results, fq, position)
+	if err != nil {
+		return nil, store.Page{}, err
+	}
+	return store.Paginate(results, fq, position)
+}
+
+// matchWords reports whether every word of query occurs in texts, ignoring
+// case, and ranks the match by how often the words occur.
+func matchWords(query string, texts ...string) (float32, bool) {
+	text := strings.ToLower(strings.Join(texts, " "))
+
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return 0, false
+	}
+
+	var rank float32
+	for _, word := range words {
+		n := strings.Count(text, word)
+		if n == 0 {
+			return 0, false
+		}
+		rank += float32(n) / 10
+	}
+	return rank, true
+}
+
+// htmlEscaper escapes text like the escapeHTML SQL expression.
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// highlight escapes text and wraps the words of query found in it the way
+// ts_headline does.
+func highlight(query, text string) string {
+	text = htmlEscaper.Replace(text)
+	for _, word := range strings.Fields(query) {
+		re := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(word))
+		text = re.ReplaceAllString(text, "<mark>$0</mark>")
+	}
+	return text
+}
+
+func containsAll(tags, want []string) bool {
+	for _, tag := range want {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// mockKeyset does in memory what the Postgres queries do in SQL: it orders
+// items by their keyset position, drops those not past fq.Position, skips
+// fq.Offset and keeps one more than fq.Limit so Paginate can tell whether
+// there is another page.
+func mockKeyset[T any](items []T, fq store.PaginatedFeedQuery, position func(T) (store.Cursor, error)) ([]T, error) {
+	op, order := fq.Keyset()
+
+	positions := make(map[int]store.Cursor, len(items))
+	for i, item := range items {
+		c, err := position(item)
+		if err != nil {
+			return nil, err
+		}
+		positions[i] = c
+	}
+
+	indexes := make([]int, 0, len(items))
+	for i := range items {
+		if fq.Position != nil {
+			cmp := compareCursors(positions[i], *fq.Position)
+			if (op == ">" && cmp <= 0) || (op == "<" && cmp >= 0) {
+				continue
+			}
+		}
+		indexes = append(indexes, i)
+	}
+
+	sort.Slice(indexes, func(i, j int) bool {
+		cmp := compareCursors(positions[indexes[i]], positions[indexes[j]])
+		if order == "desc" {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+
+	if fq.Offset >= len(indexes) {
+		return []T{}, nil
+	}
+	indexes = indexes[fq.Offset:]
+	if len(indexes) > fq.Limit+1 {
+		indexes = indexes[:fq.Limit+1]
+	}
+
+	page := make([]T, len(indexes))
+	for i, idx := range indexes {
+		page[i] = items[idx]
+	}
+	return page, nil
+}
+
+// compareCursors orders keyset positions by (created_at, rank, id). Only one
+// of created_at and rank is set for a given list.
+func compareCursors(a, b store.Cursor) int {
+	switch {
+	case a.CreatedAt.Before(b.CreatedAt):
+		return -1
+	case a.CreatedAt.After(b.CreatedAt):
+		return 1
+	case a.Rank < b.Rank:
+		return -1
+	case a.Rank > b.Rank:
+		return 1
+	case a.ID < b.ID:
+		return -1
+	case a.ID > b.ID:
+		return 1
+	}
+	return 0
+}
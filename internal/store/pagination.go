@@ -1,6 +1,11 @@
 package store
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -8,6 +13,8 @@ import (
 	"time"
 )
 
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 type PaginatedFeedQuery struct {
 	Limit  int      `json:"limit" validate:"gte=1,lte=20"`
 	Offset int      `json:"offset" validate:"gte=0"`
@@ -16,6 +23,160 @@ type PaginatedFeedQuery struct {
 	Search string   `json:"search" validate:"max=100"`
 	Since  string   `json:"since"`
 	Until  string   `json:"until"`
+	Cursor string   `json:"cursor" validate:"max=512"`
+	// Position is the decoded Cursor, nil when paging by offset.
+	Position *Cursor `json:"-"`
+}
+
+// Cursor is a keyset position in a list ordered by (created_at, id), or by
+// (rank, id) for search results. Backward cursors page towards the start of
+// the list.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	Rank      float32   `json:"r,omitempty"`
+	ID        int64     `json:"id"`
+	Backward  bool      `json:"b,omitempty"`
+}
+
+// Page holds the cursors for the pages around a result set. A nil cursor
+// means there is nothing more in that direction.
+type Page struct {
+	Next *Cursor
+	Prev *Cursor
+}
+
+// Encode serializes the cursor and signs it with secret so clients can't
+// forge positions.
+func (c Cursor) Encode(secret []byte) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+func DecodeCursor(token string, secret []byte) (*Cursor, error) {
+	encPayload, encSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encPayload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encSig)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// DecodeCursor resolves the raw Cursor into Position. Offset is ignored when
+// a cursor is given.
+func (fq PaginatedFeedQuery) DecodeCursor(secret []byte) (PaginatedFeedQuery, error) {
+	if fq.Cursor == "" {
+		return fq, nil
+	}
+
+	c, err := DecodeCursor(fq.Cursor, secret)
+	if err != nil {
+		return fq, err
+	}
+	fq.Position = c
+	fq.Offset = 0
+
+	return fq, nil
+}
+
+// Keyset returns the comparison operator and order for fetching the page
+// after (or before, for backward cursors) Position.
+func (fq PaginatedFeedQuery) Keyset() (op string, order string) {
+	backward := fq.Position != nil && fq.Position.Backward
+
+	order = fq.Sort
+	if backward {
+		order = reverseSort(fq.Sort)
+	}
+
+	if order == "desc" {
+		return "<", order
+	}
+	return ">", order
+}
+
+func reverseSort(sort string) string {
+	if sort == "desc" {
+		return "asc"
+	}
+	return "desc"
+}
+
+// Paginate trims the limit+1 rows fetched for fq back to limit, restores
+// the requested order for backward pages and works out the surrounding
+// cursors. position returns the keyset position of an item.
+func Paginate[T any](items []T, fq PaginatedFeedQuery, position func(T) (Cursor, error)) ([]T, Page, error) {
+	var page Page
+
+	backward := fq.Position != nil && fq.Position.Backward
+	hasMore := len(items) > fq.Limit
+	if hasMore {
+		items = items[:fq.Limit]
+	}
+
+	if backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	if len(items) == 0 {
+		return items, page, nil
+	}
+
+	first, err := position(items[0])
+	if err != nil {
+		return nil, page, err
+	}
+	last, err := position(items[len(items)-1])
+	if err != nil {
+		return nil, page, err
+	}
+	first.Backward = true
+
+	switch {
+	case backward:
+		page.Next = &last
+		if hasMore {
+			page.Prev = &first
+		}
+	default:
+		if hasMore {
+			page.Next = &last
+		}
+		if fq.Position != nil || fq.Offset > 0 {
+			page.Prev = &first
+		}
+	}
+
+	return items, page, nil
 }
 
 func (fq PaginatedFeedQuery) Parse(r *http.Request) (PaginatedFeedQuery, error) {
@@ -63,6 +224,11 @@ func (fq PaginatedFeedQuery) Parse(r *http.Request) (PaginatedFeedQuery, error)
 		}
 		fq.Until = u
 	}
+	cursor := qs.Get("cursor")
+	if cursor != "" {
+		fq.Cursor = cursor
+	}
+
 	tags := qs.Get("tags")
 	if tags != "" {
 		fq.Tags = strings.Split(tags, ",")
@@ -3,22 +3,83 @@ package store
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
 	"time"
 
 	"github.com/lib/pq"
 )
 
+// Visibility levels of a post. Besides its author, a public post can be seen
+// by everyone, a followers-only post by the author's followers and a
+// mentioned-only post by the users it @mentions.
+const (
+	PostPublic    = "public"
+	PostFollowers = "followers"
+	PostMentioned = "mentioned"
+)
+
+// usernameGrammar is the form of a username, letters, digits, '_', '.' and
+// '-' ending in a letter, digit or '_', so that every user can be mentioned.
+const usernameGrammar = `[\w.-]*\w`
+
+var usernamePattern = regexp.MustCompile(`^` + usernameGrammar + `$`)
+
+// mentionPattern matches @username mentions, but not the @ of an email.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@(` + usernameGrammar + `)`)
+
+// ValidUsername reports whether username can be @mentioned.
+func ValidUsername(username string) bool {
+	return usernamePattern.MatchString(username)
+}
+
+// MentionsIn returns the usernames mentioned in texts, in order and without
+// duplicates.
+func MentionsIn(texts ...string) []string {
+	mentions := []string{}
+	for _, text := range texts {
+		for _, m := range mentionPattern.FindAllStringSubmatch(text, -1) {
+			if !slices.Contains(mentions, m[1]) {
+				mentions = append(mentions, m[1])
+			}
+		}
+	}
+	return mentions
+}
+
+// visibleTo returns the SQL condition under which the visibility of the post
+// aliased post lets the user whose ID is in the viewer parameter see it.
+func visibleTo(post, viewer string) string {
+	return fmt.Sprintf(`(%[1]s.visibility = 'public' OR %[1]s.user_id = %[2]s OR
+	(%[1]s.visibility = 'followers' AND EXISTS (
+		SELECT 1 FROM followers vf WHERE vf.user_id = %[2]s AND vf.follower_id = %[1]s.user_id
+	)) OR
+	(%[1]s.visibility = 'mentioned' AND %[2]s = ANY(%[1]s.mentioned_ids)))`, post, viewer)
+}
+
 type Post struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	UserID    int64     `json:"user_id"`
-	Tags      []string  `json:"tags"`
-	CreatedAt string    `json:"created_at"`
-	UpdatedAt string    `json:"updated_at"`
-	Comments  []Comment `json:"comments"`
-	User      User      `json:"user"`
-	Version   int       `json:"version"`
+	ID        int64           `json:"id"`
+	Title     string          `json:"title"`
+	Content   string          `json:"content"`
+	UserID    int64           `json:"user_id"`
+	Tags      []string        `json:"tags"`
+	CreatedAt string          `json:"created_at"`
+	UpdatedAt string          `json:"updated_at"`
+	Comments  []Comment       `json:"comments"`
+	User      User            `json:"user"`
+	Version   int             `json:"version"`
+	Reactions []ReactionCount `json:"reactions"`
+	// Visibility is one of PostPublic, PostFollowers or PostMentioned.
+	Visibility string `json:"visibility"`
+	// Mentions are the usernames @mentioned in the title and content.
+	Mentions []string `json:"mentions"`
+	// MentionedIDs are the IDs of the users Mentions named when the post was
+	// written, which a mentioned-only post is shown to. They don't follow
+	// later renames, so a freed username doesn't grant access to the post.
+	MentionedIDs []int64 `json:"-"`
+	// DeletedAt is set while the post is in the trash.
+	DeletedAt *string `json:"deleted_at,omitempty"`
 }
 
 type PostWithMetadata struct {
@@ -31,12 +92,18 @@ type PostStore struct {
 
 func (s *PostStore) Create(ctx context.Context, post *Post) error {
 	query := `
-		INSERT INTO posts (content,title,user_id,tags)
-		VALUES ($1,$2,$3,$4) RETURNING id,created_at,updated_at
+		INSERT INTO posts (content,title,user_id,tags,visibility,mentions,mentioned_ids)
+		VALUES ($1,$2,$3,$4,$5,$6,ARRAY(SELECT id FROM users WHERE username = ANY($6)))
+		RETURNING id,created_at,updated_at,mentioned_ids
 	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
+	if post.Visibility == "" {
+		post.Visibility = PostPublic
+	}
+	post.Mentions = MentionsIn(post.Title, post.Content)
+
 	err := s.db.QueryRowContext(
 		ctx,
 		query,
@@ -44,10 +111,13 @@ func (s *PostStore) Create(ctx context.Context, post *Post) error {
 		post.Title,
 		post.UserID,
 		pq.Array(post.Tags),
+		post.Visibility,
+		pq.Array(post.Mentions),
 	).Scan(
 		&post.ID,
 		&post.CreatedAt,
 		&post.UpdatedAt,
+		pq.Array(&post.MentionedIDs),
 	)
 	if err != nil {
 		return err
@@ -58,9 +128,9 @@ func (s *PostStore) Create(ctx context.Context, post *Post) error {
 
 func (s *PostStore) GetByID(ctx context.Context, postID int64) (*Post, error) {
 	query := `
-	SELECT id,content,title,user_id,tags,version,
+	SELECT id,content,title,user_id,tags,version,visibility,mentions,mentioned_ids,
 	created_at,updated_at FROM posts 
-	WHERE id = $1
+	WHERE id = $1 AND deleted_at IS NULL
 	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
@@ -77,6 +147,9 @@ func (s *PostStore) GetByID(ctx context.Context, postID int64) (*Post, error) {
 		&post.UserID,
 		pq.Array(&post.Tags),
 		&post.Version,
+		&post.Visibility,
+		pq.Array(&post.Mentions),
+		pq.Array(&post.MentionedIDs),
 		&post.CreatedAt,
 		&post.UpdatedAt,
 	)
@@ -92,25 +165,101 @@ func (s *PostStore) GetByID(ctx context.Context, postID int64) (*Post, error) {
 	return &post, nil
 }
 
-func (s *PostStore) Delete(ctx context.Context, postID int64) error {
+// Delete moves the post to the trash, from where it can be restored until
+// it gets purged. Like Update, it only applies while the post is still at
+// version, and reports ErrConflict otherwise.
+func (s *PostStore) Delete(ctx context.Context, postID int64, version int) error {
+	query := `
+	UPDATE posts SET deleted_at = NOW()
+	WHERE id = $1 AND version = $2 AND deleted_at IS NULL
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(
+			ctx,
+			query,
+			postID,
+			version,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return updateConflict(ctx, tx, postID)
+		}
+
+		return nil
+	})
+}
+
+// GetTrashed returns the post if it is in the trash.
+func (s *PostStore) GetTrashed(ctx context.Context, postID int64) (*Post, error) {
 	query := `
-	DELETE FROM posts 
-	WHERE id = $1 
+	SELECT id,content,title,user_id,tags,version,visibility,mentions,mentioned_ids,
+	created_at,updated_at,deleted_at FROM posts
+	WHERE id = $1 AND deleted_at IS NOT NULL
 	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	res, err := s.db.ExecContext(
+	var post Post
+	err := s.db.QueryRowContext(
 		ctx,
 		query,
 		postID,
+	).Scan(
+		&post.ID,
+		&post.Content,
+		&post.Title,
+		&post.UserID,
+		pq.Array(&post.Tags),
+		&post.Version,
+		&post.Visibility,
+		pq.Array(&post.Mentions),
+		pq.Array(&post.MentionedIDs),
+		&post.CreatedAt,
+		&post.UpdatedAt,
+		&post.DeletedAt,
 	)
 
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &post, nil
+}
+
+// Restore takes the post back out of the trash, provided it was deleted
+// after deletedSince.
+func (s *PostStore) Restore(ctx context.Context, postID int64, deletedSince time.Time) error {
+	query := `
+	UPDATE posts SET deleted_at = NULL
+	WHERE id = $1 AND deleted_at >= $2
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, postID, deletedSince)
 	if err != nil {
 		return err
 	}
 
 	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
 	if rows == 0 {
 		return ErrNotFound
 	}
@@ -118,37 +267,76 @@ func (s *PostStore) Delete(ctx context.Context, postID int64) error {
 	return nil
 }
 
+// Purge permanently deletes the posts trashed before deletedBefore, along
+// with their comments, reactions and revisions, and returns how many posts
+// were deleted.
+func (s *PostStore) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	query := `
+	DELETE FROM posts
+	WHERE deleted_at < $1
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Update saves post as a new version, keeping the previous one as a
+// revision. It fails with ErrConflict unless post.Version is still the
+// latest version.
 func (s *PostStore) Update(ctx context.Context, post *Post) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := createRevision(ctx, tx, post.ID, post.Version); err != nil {
+			return err
+		}
+		return s.update(ctx, tx, post)
+	})
+}
+
+func (s *PostStore) update(ctx context.Context, tx *sql.Tx, post *Post) error {
 	query := `
 		UPDATE posts 
 		SET 
 		title = $1,
 		content = $2,
 		tags = $3,
+		visibility = $6,
+		mentions = $7,
+		mentioned_ids = ARRAY(SELECT id FROM users WHERE username = ANY($7)),
 		updated_at = NOW(),
 		version = version + 1 
-		where id = $4 AND version = $5 
-		RETURNING version
+		where id = $4 AND version = $5 AND deleted_at IS NULL
+		RETURNING version,mentioned_ids
 	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	err := s.db.QueryRowContext(
+	post.Mentions = MentionsIn(post.Title, post.Content)
+
+	err := tx.QueryRowContext(
 		ctx,
 		query,
 		post.Title,
 		post.Content,
-		post.Tags,
+		pq.Array(post.Tags),
 		post.ID,
 		post.Version,
+		post.Visibility,
+		pq.Array(post.Mentions),
 	).Scan(
 		&post.Version,
+		pq.Array(&post.MentionedIDs),
 	)
 
 	if err != nil {
 		switch err {
 		case sql.ErrNoRows:
-			return ErrNotFound
+			return updateConflict(ctx, tx, post.ID)
 		default:
 			return err
 		}
@@ -158,7 +346,23 @@ func (s *PostStore) Update(ctx context.Context, post *Post) error {
 
 }
 
-func (s *PostStore) GetUserFeed(ctx context.Context, postID int64, fq PaginatedFeedQuery) ([]PostWithMetadata, error) {
+// updateConflict tells why an update of postID matched no row: ErrConflict
+// when the post exists with another version, ErrNotFound otherwise.
+func updateConflict(ctx context.Context, tx *sql.Tx, postID int64) error {
+	query := `SELECT EXISTS (SELECT 1 FROM posts WHERE id = $1 AND deleted_at IS NULL)`
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, query, postID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return ErrConflict
+	}
+	return ErrNotFound
+}
+
+func (s *PostStore) GetUserFeed(ctx context.Context, userID int64, fq PaginatedFeedQuery) ([]PostWithMetadata, Page, error) {
+	op, order := fq.Keyset()
 	query := `
 	select
 	p.id,
@@ -167,25 +371,37 @@ func (s *PostStore) GetUserFeed(ctx context.Context, postID int64, fq PaginatedF
 	p.content,
 	p.tags,
 	p.version,
+	p.visibility,
+	p.mentions,
 	p.created_at,
 	p.updated_at,
 	u.username,
 	count(c.id) as comments_count
 	from posts as p
 	left join users as u on u.id = p.user_id
-	left join comments as c on c.post_id = p.id
+	left join comments as c on c.post_id = p.id and c.deleted_at is null
 	left join followers as f on f.user_id = $1 and f.follower_id = p.user_id
 	where (f.follower_id is not null or p.user_id = $1) and
-	(p.title ILIKE '%' || $4 || '%' or p.content ILIKE '%' || $4 || '%') and
+	p.deleted_at is null and
+	` + visibleTo("p", "$1") + ` and
+	not exists (select 1 from user_mutes m where m.muter_id = $1 and m.muted_id = p.user_id) and
+	not exists (
+		select 1 from user_blocks b
+		where (b.blocker_id = $1 and b.blocked_id = p.user_id) or (b.blocker_id = p.user_id and b.blocked_id = $1)
+	) and
+	($4 = '' or p.search_vector @@ websearch_to_tsquery('english', $4)) and
 	(p.tags @> $5 or $5 = '{}' ) and
-	(p.created_at between $6 and $7 or $6 IS NULL or $7 IS NULL)
+	(p.created_at between $6 and $7 or $6 IS NULL or $7 IS NULL) and
+	($8::timestamptz IS NULL or (p.created_at, p.id) ` + op + ` ($8, $9::bigint))
 	group by p.id,u.username
-	order by p.created_at ` + fq.Sort + `
+	order by p.created_at ` + order + `, p.id ` + order + `
 	limit $2 offset $3
 	`
 	feed := []PostWithMetadata{}
 	var since *time.Time
 	var until *time.Time
+	var afterTime *time.Time
+	var afterID *int64
 	var err error
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
@@ -193,34 +409,41 @@ func (s *PostStore) GetUserFeed(ctx context.Context, postID int64, fq PaginatedF
 	if fq.Since != "" {
 		t, err := time.Parse(time.DateTime, fq.Since)
 		if err != nil {
-			return nil, err
+			return nil, Page{}, err
 		}
 		since = &t
 	}
 	if fq.Until != "" {
 		t, err := time.Parse(time.DateTime, fq.Until)
 		if err != nil {
-			return nil, err
+			return nil, Page{}, err
 		}
 		until = &t
 	}
+	if fq.Position != nil {
+		afterTime = &fq.Position.CreatedAt
+		afterID = &fq.Position.ID
+	}
 	rows, err := s.db.QueryContext(
 		ctx,
 		query,
-		postID,
-		fq.Limit,
+		userID,
+		// one extra row tells whether there is another page
+		fq.Limit+1,
 		fq.Offset,
 		fq.Search,
 		pq.Array(fq.Tags),
 		since,
 		until,
+		afterTime,
+		afterID,
 	)
 	if err != nil {
 		switch err {
 		case sql.ErrNoRows:
-			return nil, ErrNotFound
+			return nil, Page{}, ErrNotFound
 		default:
-			return nil, err
+			return nil, Page{}, err
 		}
 	}
 	defer rows.Close()
@@ -234,18 +457,51 @@ func (s *PostStore) GetUserFeed(ctx context.Context, postID int64, fq PaginatedF
 			&post.Content,
 			pq.Array(&post.Tags),
 			&post.Version,
+			&post.Visibility,
+			pq.Array(&post.Mentions),
 			&post.CreatedAt,
 			&post.UpdatedAt,
 			&post.User.Username,
 			&post.CommentCount,
 		)
 		if err != nil {
-			return nil, err
+			return nil, Page{}, err
 		}
 
 		feed = append(feed, post)
 	}
 
-	return feed, nil
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+
+	feed, page, err := Paginate(feed, fq, func(p PostWithMetadata) (Cursor, error) {
+		return PostCursor(p.Post)
+	})
+	if err != nil {
+		return nil, Page{}, err
+	}
 
+	postIDs := make([]int64, len(feed))
+	for i, post := range feed {
+		postIDs[i] = post.ID
+	}
+	reactions, err := getReactionCounts(ctx, s.db, postIDs, userID)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	for i := range feed {
+		feed[i].Reactions = reactions[feed[i].ID]
+	}
+
+	return feed, page, nil
+
+}
+
+func PostCursor(post Post) (Cursor, error) {
+	createdAt, err := time.Parse(time.RFC3339, post.CreatedAt)
+	if err != nil {
+		return Cursor{}, err
+	}
+	return Cursor{CreatedAt: createdAt, ID: post.ID}, nil
 }
@@ -0,0 +1,215 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type Reaction struct {
+	PostID    int64  `json:"post_id"`
+	UserID    int64  `json:"user_id"`
+	Kind      string `json:"kind"`
+	CreatedAt string `json:"created_at"`
+	User      User   `json:"user"`
+}
+
+// ReactionCount aggregates the reactions of one kind on a post, relative to
+// the user viewing it.
+type ReactionCount struct {
+	Kind        string `json:"kind"`
+	Count       int    `json:"count"`
+	ReactedByMe bool   `json:"reacted_by_me"`
+}
+
+type ReactionQuery struct {
+	Limit  int    `json:"limit" validate:"gte=1,lte=50"`
+	Cursor string `json:"cursor" validate:"max=512"`
+	// Position is the decoded Cursor, nil for the first page.
+	Position *Cursor `json:"-"`
+}
+
+func (rq ReactionQuery) Parse(r *http.Request) (ReactionQuery, error) {
+	qs := r.URL.Query()
+
+	limit := qs.Get("limit")
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return rq, err
+		}
+		rq.Limit = l
+	}
+
+	cursor := qs.Get("cursor")
+	if cursor != "" {
+		rq.Cursor = cursor
+	}
+
+	return rq, nil
+}
+
+func (rq ReactionQuery) DecodeCursor(secret []byte) (ReactionQuery, error) {
+	if rq.Cursor == "" {
+		return rq, nil
+	}
+
+	c, err := DecodeCursor(rq.Cursor, secret)
+	if err != nil {
+		return rq, err
+	}
+	rq.Position = c
+
+	return rq, nil
+}
+
+func (rq ReactionQuery) FeedQuery() PaginatedFeedQuery {
+	return PaginatedFeedQuery{
+		Limit:    rq.Limit,
+		Sort:     "desc",
+		Position: rq.Position,
+	}
+}
+
+type ReactionStore struct {
+	db *sql.DB
+}
+
+// Add records the reaction, keeping the existing one if the user already
+// reacted to the post with the same kind.
+func (s *ReactionStore) Add(ctx context.Context, reaction *Reaction) error {
+	query := `
+	INSERT INTO post_reactions (post_id,user_id,kind)
+	VALUES ($1,$2,$3)
+	ON CONFLICT (post_id,user_id,kind) DO UPDATE SET kind = EXCLUDED.kind
+	RETURNING created_at
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		reaction.PostID,
+		reaction.UserID,
+		reaction.Kind,
+	).Scan(&reaction.CreatedAt)
+}
+
+func (s *ReactionStore) Remove(ctx context.Context, postID, userID int64, kind string) error {
+	query := `
+	DELETE FROM post_reactions
+	WHERE post_id = $1 AND user_id = $2 AND kind = $3
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, postID, userID, kind)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *ReactionStore) GetCounts(ctx context.Context, postIDs []int64, userID int64) (map[int64][]ReactionCount, error) {
+	return getReactionCounts(ctx, s.db, postIDs, userID)
+}
+
+// GetByPostID returns a page of the users who reacted to the post with kind,
+// most recent first.
+func (s *ReactionStore) GetByPostID(ctx context.Context, postID int64, kind string, rq ReactionQuery) ([]Reaction, Page, error) {
+	fq := rq.FeedQuery()
+	op, order := fq.Keyset()
+	query := `
+	SELECT r.post_id,r.user_id,r.kind,r.created_at,u.username
+	FROM post_reactions r
+	JOIN users u ON u.id = r.user_id
+	WHERE r.post_id = $1 AND r.kind = $2 AND
+	($4::timestamptz IS NULL OR (r.created_at, r.user_id) ` + op + ` ($4, $5::bigint))
+	ORDER BY r.created_at ` + order + `, r.user_id ` + order + `
+	LIMIT $3
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var afterTime, afterID any
+	if rq.Position != nil {
+		afterTime = rq.Position.CreatedAt
+		afterID = rq.Position.ID
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, postID, kind, rq.Limit+1, afterTime, afterID)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	defer rows.Close()
+
+	reactions := []Reaction{}
+	for rows.Next() {
+		var r Reaction
+		if err := rows.Scan(&r.PostID, &r.UserID, &r.Kind, &r.CreatedAt, &r.User.Username); err != nil {
+			return nil, Page{}, err
+		}
+		r.User.ID = r.UserID
+		reactions = append(reactions, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+
+	return Paginate(reactions, fq, func(r Reaction) (Cursor, error) {
+		createdAt, err := time.Parse(time.RFC3339, r.CreatedAt)
+		if err != nil {
+			return Cursor{}, err
+		}
+		return Cursor{CreatedAt: createdAt, ID: r.UserID}, nil
+	})
+}
+
+// getReactionCounts aggregates the reactions of every post in postIDs,
+// flagging the kinds userID reacted with.
+func getReactionCounts(ctx context.Context, db *sql.DB, postIDs []int64, userID int64) (map[int64][]ReactionCount, error) {
+	query := `
+	SELECT post_id,kind,count(*),bool_or(user_id = $2)
+	FROM post_reactions
+	WHERE post_id = ANY($1)
+	GROUP BY post_id,kind
+	ORDER BY post_id,kind
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	counts := map[int64][]ReactionCount{}
+	if len(postIDs) == 0 {
+		return counts, nil
+	}
+
+	rows, err := db.QueryContext(ctx, query, pq.Array(postIDs), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID int64
+		var rc ReactionCount
+		if err := rows.Scan(&postID, &rc.Kind, &rc.Count, &rc.ReactedByMe); err != nil {
+			return nil, err
+		}
+		counts[postID] = append(counts[postID], rc)
+	}
+	return counts, rows.Err()
+}
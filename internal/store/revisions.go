@@ -0,0 +1,193 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/lib/pq"
+)
+
+// PostRevision is a post as it was at Version, saved when the post was
+// edited past it.
+type PostRevision struct {
+	PostID  int64    `json:"post_id"`
+	Version int      `json:"version"`
+	Title   string   `json:"title"`
+	Content string   `json:"content"`
+	Tags    []string `json:"tags"`
+	// CreatedAt is when the post was saved at Version.
+	CreatedAt string `json:"created_at"`
+}
+
+type RevisionQuery struct {
+	Limit  int    `json:"limit" validate:"gte=1,lte=50"`
+	Cursor string `json:"cursor" validate:"max=512"`
+	// Position is the decoded Cursor, nil for the first page.
+	Position *Cursor `json:"-"`
+}
+
+func (rq RevisionQuery) Parse(r *http.Request) (RevisionQuery, error) {
+	qs := r.URL.Query()
+
+	limit := qs.Get("limit")
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return rq, err
+		}
+		rq.Limit = l
+	}
+
+	cursor := qs.Get("cursor")
+	if cursor != "" {
+		rq.Cursor = cursor
+	}
+
+	return rq, nil
+}
+
+func (rq RevisionQuery) DecodeCursor(secret []byte) (RevisionQuery, error) {
+	if rq.Cursor == "" {
+		return rq, nil
+	}
+
+	c, err := DecodeCursor(rq.Cursor, secret)
+	if err != nil {
+		return rq, err
+	}
+	rq.Position = c
+
+	return rq, nil
+}
+
+func (rq RevisionQuery) FeedQuery() PaginatedFeedQuery {
+	return PaginatedFeedQuery{
+		Limit:    rq.Limit,
+		Sort:     "desc",
+		Position: rq.Position,
+	}
+}
+
+// RevisionPosition keys revisions by version alone, which orders them the
+// same way as their creation time.
+func RevisionPosition(rev PostRevision) (Cursor, error) {
+	return Cursor{ID: int64(rev.Version)}, nil
+}
+
+type RevisionStore struct {
+	db *sql.DB
+}
+
+// GetByPostID returns a page of the previous versions of the post, most
+// recent first.
+func (s *RevisionStore) GetByPostID(ctx context.Context, postID int64, rq RevisionQuery) ([]PostRevision, Page, error) {
+	fq := rq.FeedQuery()
+	op, order := fq.Keyset()
+	query := `
+	SELECT post_id,version,title,content,tags,created_at
+	FROM post_revisions
+	WHERE post_id = $1 AND ($3::int IS NULL OR version ` + op + ` $3)
+	ORDER BY version ` + order + `
+	LIMIT $2
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var after any
+	if rq.Position != nil {
+		after = rq.Position.ID
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, postID, rq.Limit+1, after)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	defer rows.Close()
+
+	revisions := []PostRevision{}
+	for rows.Next() {
+		var rev PostRevision
+		if err := rows.Scan(
+			&rev.PostID,
+			&rev.Version,
+			&rev.Title,
+			&rev.Content,
+			pq.Array(&rev.Tags),
+			&rev.CreatedAt,
+		); err != nil {
+			return nil, Page{}, err
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+
+	return Paginate(revisions, fq, RevisionPosition)
+}
+
+func (s *RevisionStore) GetByVersion(ctx context.Context, postID int64, version int) (*PostRevision, error) {
+	query := `
+	SELECT post_id,version,title,content,tags,created_at
+	FROM post_revisions
+	WHERE post_id = $1 AND version = $2
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var rev PostRevision
+	err := s.db.QueryRowContext(ctx, query, postID, version).Scan(
+		&rev.PostID,
+		&rev.Version,
+		&rev.Title,
+		&rev.Content,
+		pq.Array(&rev.Tags),
+		&rev.CreatedAt,
+	)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &rev, nil
+}
+
+// createRevision saves the post as it is at version before it gets
+// updated, failing with ErrConflict when the post moved past version.
+func createRevision(ctx context.Context, tx *sql.Tx, postID int64, version int) error {
+	query := `
+	INSERT INTO post_revisions (post_id,version,title,content,tags,created_at)
+	SELECT id,version,title,content,tags,updated_at
+	FROM posts
+	WHERE id = $1 AND version = $2 AND deleted_at IS NULL
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := tx.ExecContext(ctx, query, postID, version)
+	if err != nil {
+		// a concurrent update saved this version first
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrConflict
+		}
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return updateConflict(ctx, tx, postID)
+	}
+
+	return nil
+}
@@ -0,0 +1,293 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/lib/pq"
+)
+
+// headlineOptions configures the ts_headline snippets returned with results.
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10"
+
+// escapeHTML returns the SQL expression escaping the text of expr for HTML,
+// so that the <mark> tags added by ts_headline are the only markup in a
+// snippet.
+func escapeHTML(expr string) string {
+	return `replace(replace(replace(` + expr + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+}
+
+type SearchQuery struct {
+	Query  string `json:"q" validate:"required,max=100"`
+	Type   string `json:"type" validate:"oneof=posts users comments"`
+	Limit  int    `json:"limit" validate:"gte=1,lte=20"`
+	Cursor string `json:"cursor" validate:"max=512"`
+	// Position is the decoded Cursor, nil for the first page.
+	Position *Cursor `json:"-"`
+	// ViewerID leaves out the posts and comments of users who blocked the
+	// viewer or were blocked by them, and those on the posts of private
+	// accounts the viewer doesn't follow.
+	ViewerID int64 `json:"-"`
+}
+
+// The Snippet of a search result is HTML: the matched text, escaped, with
+// the matches wrapped in <mark> tags.
+type PostSearchResult struct {
+	Post
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+type UserSearchResult struct {
+	ID       int64   `json:"id"`
+	Username string  `json:"username"`
+	Rank     float32 `json:"rank"`
+	Snippet  string  `json:"snippet"`
+}
+
+type CommentSearchResult struct {
+	Comment
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+func (sq SearchQuery) Parse(r *http.Request) (SearchQuery, error) {
+	qs := r.URL.Query()
+
+	sq.Query = qs.Get("q")
+
+	searchType := qs.Get("type")
+	if searchType != "" {
+		sq.Type = searchType
+	}
+
+	limit := qs.Get("limit")
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return sq, err
+		}
+		sq.Limit = l
+	}
+
+	cursor := qs.Get("cursor")
+	if cursor != "" {
+		sq.Cursor = cursor
+	}
+
+	return sq, nil
+}
+
+func (sq SearchQuery) DecodeCursor(secret []byte) (SearchQuery, error) {
+	if sq.Cursor == "" {
+		return sq, nil
+	}
+
+	c, err := DecodeCursor(sq.Cursor, secret)
+	if err != nil {
+		return sq, err
+	}
+	sq.Position = c
+
+	return sq, nil
+}
+
+// FeedQuery maps sq onto the feed query so search results page the same
+// way the feed does, ordered by (rank, id) from best to worst match.
+func (sq SearchQuery) FeedQuery() PaginatedFeedQuery {
+	return PaginatedFeedQuery{
+		Limit:    sq.Limit,
+		Sort:     "desc",
+		Position: sq.Position,
+	}
+}
+
+// keysetArgs returns the rank and id to page from, both nil on the first page.
+func (sq SearchQuery) keysetArgs() (*float32, *int64) {
+	if sq.Position == nil {
+		return nil, nil
+	}
+	return &sq.Position.Rank, &sq.Position.ID
+}
+
+type SearchStore struct {
+	db *sql.DB
+}
+
+func (s *SearchStore) Posts(ctx context.Context, sq SearchQuery) ([]PostSearchResult, Page, error) {
+	fq := sq.FeedQuery()
+	op, order := fq.Keyset()
+	query := `
+	SELECT r.id,r.user_id,r.title,r.content,r.tags,r.version,r.visibility,r.mentions,r.created_at,r.updated_at,
+	r.username,r.rank,
+	ts_headline('english', ` + escapeHTML("r.content") + `, websearch_to_tsquery('english', $1), '` + headlineOptions + `')
+	FROM (
+		SELECT p.id,p.user_id,p.title,p.content,p.tags,p.version,p.visibility,p.mentions,p.created_at,p.updated_at,
+		u.username,ts_rank(p.search_vector, q) AS rank
+		FROM posts p
+		JOIN users u ON u.id = p.user_id,
+		websearch_to_tsquery('english', $1) q
+		WHERE p.search_vector @@ q AND p.deleted_at IS NULL AND
+		NOT EXISTS (
+			SELECT 1 FROM user_blocks b
+			WHERE (b.blocker_id = $5 AND b.blocked_id = p.user_id) OR (b.blocker_id = p.user_id AND b.blocked_id = $5)
+		) AND
+		(NOT u.is_private OR p.user_id = $5 OR
+		EXISTS (SELECT 1 FROM followers f WHERE f.user_id = $5 AND f.follower_id = p.user_id)) AND
+		` + visibleTo("p", "$5") + `
+	) r
+	WHERE ($3::real IS NULL OR (r.rank, r.id) ` + op + ` ($3::real, $4::bigint))
+	ORDER BY r.rank ` + order + `, r.id ` + order + `
+	LIMIT $2
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rank, id := sq.keysetArgs()
+	rows, err := s.db.QueryContext(ctx, query, sq.Query, sq.Limit+1, rank, id, sq.ViewerID)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	defer rows.Close()
+
+	results := []PostSearchResult{}
+	for rows.Next() {
+		var res PostSearchResult
+		if err := rows.Scan(
+			&res.ID,
+			&res.UserID,
+			&res.Title,
+			&res.Content,
+			pq.Array(&res.Tags),
+			&res.Version,
+			&res.Visibility,
+			pq.Array(&res.Mentions),
+			&res.CreatedAt,
+			&res.UpdatedAt,
+			&res.User.Username,
+			&res.Rank,
+			&res.Snippet,
+		); err != nil {
+			return nil, Page{}, err
+		}
+		res.User.ID = res.UserID
+		results = append(results, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+
+	return Paginate(results, fq, func(r PostSearchResult) (Cursor, error) {
+		return Cursor{Rank: r.Rank, ID: r.ID}, nil
+	})
+}
+
+func (s *SearchStore) Users(ctx context.Context, sq SearchQuery) ([]UserSearchResult, Page, error) {
+	fq := sq.FeedQuery()
+	op, order := fq.Keyset()
+	query := `
+	SELECT r.id,r.username,r.rank,
+	ts_headline('simple', ` + escapeHTML("r.username") + `, websearch_to_tsquery('simple', $1), '` + headlineOptions + `')
+	FROM (
+		SELECT u.id,u.username,ts_rank(u.search_vector, q) AS rank
+		FROM users u,
+		websearch_to_tsquery('simple', $1) q
+		WHERE u.search_vector @@ q AND u.is_active = true
+	) r
+	WHERE ($3::real IS NULL OR (r.rank, r.id) ` + op + ` ($3::real, $4::bigint))
+	ORDER BY r.rank ` + order + `, r.id ` + order + `
+	LIMIT $2
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rank, id := sq.keysetArgs()
+	rows, err := s.db.QueryContext(ctx, query, sq.Query, sq.Limit+1, rank, id)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	defer rows.Close()
+
+	results := []UserSearchResult{}
+	for rows.Next() {
+		var res UserSearchResult
+		if err := rows.Scan(&res.ID, &res.Username, &res.Rank, &res.Snippet); err != nil {
+			return nil, Page{}, err
+		}
+		results = append(results, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+
+	return Paginate(results, fq, func(r UserSearchResult) (Cursor, error) {
+		return Cursor{Rank: r.Rank, ID: r.ID}, nil
+	})
+}
+
+func (s *SearchStore) Comments(ctx context.Context, sq SearchQuery) ([]CommentSearchResult, Page, error) {
+	fq := sq.FeedQuery()
+	op, order := fq.Keyset()
+	query := `
+	SELECT r.id,r.post_id,r.user_id,r.content,r.created_at,r.username,r.rank,
+	ts_headline('english', ` + escapeHTML("r.content") + `, websearch_to_tsquery('english', $1), '` + headlineOptions + `')
+	FROM (
+		SELECT c.id,c.post_id,c.user_id,c.content,c.created_at,
+		u.username,ts_rank(c.search_vector, q) AS rank
+		FROM comments c
+		JOIN users u ON u.id = c.user_id
+		JOIN posts p ON p.id = c.post_id
+		JOIN users pu ON pu.id = p.user_id,
+		websearch_to_tsquery('english', $1) q
+		WHERE c.search_vector @@ q AND c.deleted_at IS NULL AND p.deleted_at IS NULL AND
+		NOT EXISTS (
+			SELECT 1 FROM user_blocks b
+			WHERE (b.blocker_id = $5 AND b.blocked_id IN (c.user_id, p.user_id)) OR
+			(b.blocker_id IN (c.user_id, p.user_id) AND b.blocked_id = $5)
+		) AND
+		(NOT pu.is_private OR p.user_id = $5 OR
+		EXISTS (SELECT 1 FROM followers f WHERE f.user_id = $5 AND f.follower_id = p.user_id)) AND
+		` + visibleTo("p", "$5") + `
+	) r
+	WHERE ($3::real IS NULL OR (r.rank, r.id) ` + op + ` ($3::real, $4::bigint))
+	ORDER BY r.rank ` + order + `, r.id ` + order + `
+	LIMIT $2
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rank, id := sq.keysetArgs()
+	rows, err := s.db.QueryContext(ctx, query, sq.Query, sq.Limit+1, rank, id, sq.ViewerID)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	defer rows.Close()
+
+	results := []CommentSearchResult{}
+	for rows.Next() {
+		var res CommentSearchResult
+		if err := rows.Scan(
+			&res.ID,
+			&res.PostID,
+			&res.UserID,
+			&res.Content,
+			&res.CreatedAt,
+			&res.User.Username,
+			&res.Rank,
+			&res.Snippet,
+		); err != nil {
+			return nil, Page{}, err
+		}
+		res.User.ID = res.UserID
+		results = append(results, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Page{}, err
+	}
+
+	return Paginate(results, fq, func(r CommentSearchResult) (Cursor, error) {
+		return Cursor{Rank: r.Rank, ID: r.ID}, nil
+	})
+}
@@ -0,0 +1,263 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+)
+
+type Session struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	FamilyID  string    `json:"-"`
+	Token     string    `json:"-"`
+	UserAgent string    `json:"user_agent"`
+	IP        string    `json:"ip"`
+	CreatedAt string    `json:"created_at"`
+	Expiry    time.Time `json:"expiry"`
+	RevokedAt *string   `json:"-"`
+}
+
+// ActiveSessions lists the session families of a user that are neither
+// revoked nor expired. Access tokens name their family in the sid claim and
+// are only accepted while it is active.
+type ActiveSessions struct {
+	UserID    int64
+	FamilyIDs []string
+	// Expiry is when the first of the families expires, zero when there are
+	// none.
+	Expiry time.Time
+}
+
+func (a *ActiveSessions) Contains(familyID string) bool {
+	return slices.Contains(a.FamilyIDs, familyID)
+}
+
+type SessionStore struct {
+	db *sql.DB
+}
+
+func (s *SessionStore) Create(ctx context.Context, session *Session) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return s.create(ctx, tx, session)
+	})
+}
+
+func (s *SessionStore) GetByToken(ctx context.Context, token string) (*Session, error) {
+	query := `
+	SELECT id,user_id,family_id,token,user_agent,ip,created_at,expiry,revoked_at
+	FROM sessions
+	WHERE token = $1
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var session Session
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		token,
+	).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.FamilyID,
+		&session.Token,
+		&session.UserAgent,
+		&session.IP,
+		&session.CreatedAt,
+		&session.Expiry,
+		&session.RevokedAt,
+	)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &session, nil
+}
+
+func (s *SessionStore) GetByUserID(ctx context.Context, userID int64) ([]Session, error) {
+	query := `
+	SELECT id,user_id,family_id,user_agent,ip,created_at,expiry
+	FROM sessions
+	WHERE user_id = $1 AND revoked_at IS NULL AND expiry > $2
+	ORDER BY created_at DESC
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		query,
+		userID,
+		time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var session Session
+		if err := rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.FamilyID,
+			&session.UserAgent,
+			&session.IP,
+			&session.CreatedAt,
+			&session.Expiry,
+		); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+func (s *SessionStore) GetActive(ctx context.Context, userID int64) (*ActiveSessions, error) {
+	query := `
+	SELECT family_id,max(expiry)
+	FROM sessions
+	WHERE user_id = $1 AND revoked_at IS NULL AND expiry > $2
+	GROUP BY family_id
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	active := &ActiveSessions{UserID: userID, FamilyIDs: []string{}}
+	for rows.Next() {
+		var familyID string
+		var expiry time.Time
+		if err := rows.Scan(&familyID, &expiry); err != nil {
+			return nil, err
+		}
+		active.FamilyIDs = append(active.FamilyIDs, familyID)
+		if active.Expiry.IsZero() || expiry.Before(active.Expiry) {
+			active.Expiry = expiry
+		}
+	}
+	return active, rows.Err()
+}
+
+// Rotate revokes the current session and issues its replacement in the same
+// family. ErrNotFound means the current session was already revoked, which
+// callers should treat as refresh token reuse.
+func (s *SessionStore) Rotate(ctx context.Context, current, next *Session) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.revoke(ctx, tx, current.ID); err != nil {
+			return err
+		}
+		next.FamilyID = current.FamilyID
+		return s.create(ctx, tx, next)
+	})
+}
+
+// RevokeFamily revokes every session in the family, which belongs to userID.
+func (s *SessionStore) RevokeFamily(ctx context.Context, userID int64, familyID string) error {
+	query := `
+	UPDATE sessions SET revoked_at = NOW()
+	WHERE user_id = $1 AND family_id = $2 AND revoked_at IS NULL
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, userID, familyID)
+	return err
+}
+
+// RevokeByID revokes every session in the family of the given session, as
+// long as it belongs to userID.
+func (s *SessionStore) RevokeByID(ctx context.Context, userID, sessionID int64) error {
+	query := `
+	UPDATE sessions SET revoked_at = NOW()
+	WHERE revoked_at IS NULL AND family_id = (
+		SELECT family_id FROM sessions WHERE id = $1 AND user_id = $2
+	)
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, sessionID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *SessionStore) create(ctx context.Context, tx *sql.Tx, session *Session) error {
+	query := `
+	INSERT INTO sessions
+	(user_id,family_id,token,user_agent,ip,expiry)
+	VALUES ($1,$2,$3,$4,$5,$6)
+	RETURNING id,created_at
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return tx.QueryRowContext(
+		ctx,
+		query,
+		session.UserID,
+		session.FamilyID,
+		session.Token,
+		session.UserAgent,
+		session.IP,
+		session.Expiry,
+	).Scan(
+		&session.ID,
+		&session.CreatedAt,
+	)
+}
+
+func (s *SessionStore) revoke(ctx context.Context, tx *sql.Tx, sessionID int64) error {
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := tx.ExecContext(ctx, query, sessionID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func revokeUserSessions(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, userID)
+	return err
+}
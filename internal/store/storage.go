@@ -9,6 +9,11 @@ import (
 
 var (
 	ErrNotFound          = errors.New("record not found")
+	ErrConflict          = errors.New("record has been modified")
+	ErrBlocked           = errors.New("one of the users has blocked the other")
+	ErrUsernameTaken     = errors.New("username is already taken")
+	ErrUsernameCooldown  = errors.New("username was changed too recently")
+	ErrAlreadyFollowing  = errors.New("already following this user")
 	QueryTimeoutDuration = time.Second * 5
 )
 
@@ -16,30 +21,98 @@ type Storage struct {
 	Posts interface {
 		Create(context.Context, *Post) error
 		GetByID(context.Context, int64) (*Post, error)
-		Delete(context.Context, int64) error
+		Delete(context.Context, int64, int) error
 		Update(context.Context, *Post) error
-		GetUserFeed(context.Context, int64, PaginatedFeedQuery) ([]PostWithMetadata, error)
+		GetUserFeed(context.Context, int64, PaginatedFeedQuery) ([]PostWithMetadata, Page, error)
+		GetTrashed(context.Context, int64) (*Post, error)
+		Restore(context.Context, int64, time.Time) error
+		Purge(context.Context, time.Time) (int64, error)
 	}
 	Users interface {
 		Create(context.Context, *sql.Tx, *User) error
 		GetByID(context.Context, int64) (*User, error)
+		GetByEmail(context.Context, string) (*User, error)
 		Follow(context.Context, int64, int64) error
 		UnFollow(context.Context, int64, int64) error
 		CreateAndInvite(context.Context, *User, string, time.Duration) error
-		Activate(context.Context, string) error
+		Activate(context.Context, string) (int64, error)
+		Delete(context.Context, int64) error
+		CreatePasswordReset(context.Context, int64, string, time.Duration) error
+		ResetPassword(context.Context, string, Password) (int64, error)
+		SetPrivate(context.Context, int64, bool) error
+		UpdateProfile(context.Context, *User, time.Time) error
 	}
 	Comments interface {
 		Create(context.Context, *Comment) error
-		GetByPostID(context.Context, int64) ([]Comment, error)
+		GetByID(context.Context, int64) (*Comment, error)
+		GetByPostID(context.Context, int64, CommentQuery) ([]Comment, Page, error)
 		Delete(context.Context, int64) error
+		GetTrashed(context.Context, int64) (*Comment, error)
+		Restore(context.Context, int64, time.Time) error
+		Purge(context.Context, time.Time) (int64, error)
+	}
+	Followers interface {
+		GetFollowers(context.Context, int64, FollowQuery) ([]Follow, Page, error)
+		GetFollowing(context.Context, int64, FollowQuery) ([]Follow, Page, error)
+		GetMutuals(context.Context, int64, FollowQuery) ([]Follow, Page, error)
+		GetStats(context.Context, int64) (*FollowStats, error)
+		GetRelationship(context.Context, int64, int64) (*Relationship, error)
+	}
+	FollowRequests interface {
+		Create(context.Context, *FollowRequest) error
+		GetPending(context.Context, int64, FollowQuery) ([]FollowRequest, Page, error)
+		Approve(context.Context, int64, int64) error
+		Reject(context.Context, int64, int64) error
+	}
+	Blocks interface {
+		Block(context.Context, int64, int64) error
+		Unblock(context.Context, int64, int64) error
+		Mute(context.Context, int64, int64) error
+		Unmute(context.Context, int64, int64) error
+		IsBlocked(context.Context, int64, int64) (bool, error)
+	}
+	Revisions interface {
+		GetByPostID(context.Context, int64, RevisionQuery) ([]PostRevision, Page, error)
+		GetByVersion(context.Context, int64, int) (*PostRevision, error)
+	}
+	Reactions interface {
+		Add(context.Context, *Reaction) error
+		Remove(context.Context, int64, int64, string) error
+		GetCounts(context.Context, []int64, int64) (map[int64][]ReactionCount, error)
+		GetByPostID(context.Context, int64, string, ReactionQuery) ([]Reaction, Page, error)
+	}
+	Search interface {
+		Posts(context.Context, SearchQuery) ([]PostSearchResult, Page, error)
+		Users(context.Context, SearchQuery) ([]UserSearchResult, Page, error)
+		Comments(context.Context, SearchQuery) ([]CommentSearchResult, Page, error)
+	}
+	Roles interface {
+		GetByName(context.Context, string) (*Role, error)
+	}
+	Sessions interface {
+		Create(context.Context, *Session) error
+		GetByToken(context.Context, string) (*Session, error)
+		GetByUserID(context.Context, int64) ([]Session, error)
+		GetActive(context.Context, int64) (*ActiveSessions, error)
+		Rotate(context.Context, *Session, *Session) error
+		RevokeFamily(context.Context, int64, string) error
+		RevokeByID(context.Context, int64, int64) error
 	}
 }
 
 func NewStorage(db *sql.DB) Storage {
 	return Storage{
-		Posts:    &PostStore{db},
-		Users:    &UserStore{db},
-		Comments: &CommentStore{db},
+		Posts:          &PostStore{db},
+		Users:          &UserStore{db},
+		Comments:       &CommentStore{db},
+		Followers:      &FollowerStore{db},
+		Blocks:         &BlockStore{db},
+		FollowRequests: &FollowRequestStore{db},
+		Revisions:      &RevisionStore{db},
+		Reactions:      &ReactionStore{db},
+		Search:         &SearchStore{db},
+		Roles:          &RoleStore{db},
+		Sessions:       &SessionStore{db},
 	}
 }
 
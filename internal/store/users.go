@@ -5,18 +5,33 @@ import (
 	"crypto/sha256"
 	"database/sql"
 	"encoding/hex"
+	"errors"
 	"time"
 
+	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type User struct {
 	ID        int64    `json:"id"`
 	Username  string   `json:"username"`
-	Email     string   `json:"email"`
+	Email     string   `json:"email,omitempty"`
 	Password  Password `json:"-"`
 	CreatedAt string   `json:"created_at"`
 	IsActive  bool     `json:"is_active"`
+	// IsPrivate accounts approve their followers and only show their posts
+	// to them.
+	IsPrivate bool  `json:"is_private"`
+	RoleID    int64 `json:"role_id"`
+	Role      Role  `json:"role"`
+	// profile details, empty until the user fills them in
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+	Website     string `json:"website"`
+	Location    string `json:"location"`
+	AvatarURL   string `json:"avatar_url"`
+	// UsernameChangedAt is when the username was last changed, if ever.
+	UsernameChangedAt *string `json:"username_changed_at,omitempty"`
 }
 
 type Password struct {
@@ -41,6 +56,17 @@ func (p *Password) Compare(text string) error {
 	return nil
 }
 
+// MarshalBinary lets encoders such as gob keep the hash, which JSON never
+// exposes, so users can be cached outside of Postgres.
+func (p Password) MarshalBinary() ([]byte, error) {
+	return p.hash, nil
+}
+
+func (p *Password) UnmarshalBinary(data []byte) error {
+	p.hash = append([]byte(nil), data...)
+	return nil
+}
+
 type UserStore struct {
 	db *sql.DB
 }
@@ -48,22 +74,29 @@ type UserStore struct {
 func (s *UserStore) Create(ctx context.Context, tx *sql.Tx, user *User) error {
 	query := `
 	INSERT INTO USERS
-	(username,email,password)
-	VALUES ($1,$2,$3)
-	RETURNING id,created_at
+	(username,email,password,role_id)
+	VALUES ($1,$2,$3,(SELECT id FROM roles WHERE name = $4))
+	RETURNING id,created_at,role_id
 	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
+	role := user.Role.Name
+	if role == "" {
+		role = "user"
+	}
+
 	err := tx.QueryRowContext(
 		ctx,
 		query,
 		user.Username,
 		user.Email,
 		user.Password.hash,
+		role,
 	).Scan(
 		&user.ID,
 		&user.CreatedAt,
+		&user.RoleID,
 	)
 
 	if err != nil {
@@ -74,9 +107,12 @@ func (s *UserStore) Create(ctx context.Context, tx *sql.Tx, user *User) error {
 
 func (s *UserStore) GetByID(ctx context.Context, userID int64) (*User, error) {
 	query := `
-	SELECT id,username,email,password,created_at,is_active
-	FROM users
-	WHERE id = $1
+	SELECT u.id,u.username,u.email,u.password,u.created_at,u.is_active,u.is_private,
+	u.display_name,u.bio,u.website,u.location,u.avatar_url,u.username_changed_at,
+	r.id,r.name,r.level,r.description
+	FROM users u
+	JOIN roles r ON r.id = u.role_id
+	WHERE u.id = $1
 	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
@@ -93,6 +129,17 @@ func (s *UserStore) GetByID(ctx context.Context, userID int64) (*User, error) {
 		&user.Password.hash,
 		&user.CreatedAt,
 		&user.IsActive,
+		&user.IsPrivate,
+		&user.DisplayName,
+		&user.Bio,
+		&user.Website,
+		&user.Location,
+		&user.AvatarURL,
+		&user.UsernameChangedAt,
+		&user.Role.ID,
+		&user.Role.Name,
+		&user.Role.Level,
+		&user.Role.Description,
 	)
 	if err != nil {
 		switch err {
@@ -102,30 +149,96 @@ func (s *UserStore) GetByID(ctx context.Context, userID int64) (*User, error) {
 			return nil, err
 		}
 	}
+	user.RoleID = user.Role.ID
 	return &user, nil
 
 }
 
+func (s *UserStore) GetByEmail(ctx context.Context, email string) (*User, error) {
+	query := `
+	SELECT u.id,u.username,u.email,u.password,u.created_at,u.is_active,u.is_private,
+	u.display_name,u.bio,u.website,u.location,u.avatar_url,u.username_changed_at,
+	r.id,r.name,r.level,r.description
+	FROM users u
+	JOIN roles r ON r.id = u.role_id
+	WHERE u.email = $1 AND u.is_active = true
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var user User
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		email,
+	).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.Password.hash,
+		&user.CreatedAt,
+		&user.IsActive,
+		&user.IsPrivate,
+		&user.DisplayName,
+		&user.Bio,
+		&user.Website,
+		&user.Location,
+		&user.AvatarURL,
+		&user.UsernameChangedAt,
+		&user.Role.ID,
+		&user.Role.Name,
+		&user.Role.Level,
+		&user.Role.Description,
+	)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	user.RoleID = user.Role.ID
+	return &user, nil
+}
+
+// Follow reports ErrBlocked if either user has blocked the other, and
+// ErrAlreadyFollowing if followerUserID already follows followingUserID.
 func (s *UserStore) Follow(ctx context.Context, followerUserID, followingUserID int64) error {
 	query := `
 	INSERT into followers 
 	(user_id,follower_id)
-	Values
-	($1,$2)
+	SELECT $1::bigint,$2::bigint
+	WHERE NOT EXISTS (
+		SELECT 1 FROM user_blocks
+		WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1)
+	)
 	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	_, err := s.db.ExecContext(
+	res, err := s.db.ExecContext(
 		ctx,
 		query,
 		followerUserID,
 		followingUserID,
 	)
 
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrAlreadyFollowing
+		}
+		return err
+	}
+
+	rows, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
+	if rows == 0 {
+		return ErrBlocked
+	}
 
 	return nil
 }
@@ -149,7 +262,10 @@ func (s *UserStore) UnFollow(ctx context.Context, followerUserID, followingUserI
 		return err
 	}
 
-	rows, _ := res.RowsAffected()
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
 
 	if rows == 0 {
 		return ErrNotFound
@@ -158,6 +274,97 @@ func (s *UserStore) UnFollow(ctx context.Context, followerUserID, followingUserI
 	return nil
 }
 
+// UpdateProfile saves the username and profile details of user. A new
+// username is refused with ErrUsernameTaken when another user has it, and
+// with ErrUsernameCooldown when the username was last changed after
+// changedBefore.
+func (s *UserStore) UpdateProfile(ctx context.Context, user *User, changedBefore time.Time) error {
+	query := `
+	UPDATE users SET
+	username = $1,
+	display_name = $2,
+	bio = $3,
+	website = $4,
+	location = $5,
+	avatar_url = $6,
+	username_changed_at = CASE WHEN username = $1 THEN username_changed_at ELSE NOW() END
+	WHERE id = $7 AND (username = $1 OR username_changed_at IS NULL OR username_changed_at < $8)
+	RETURNING username_changed_at
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		user.Username,
+		user.DisplayName,
+		user.Bio,
+		user.Website,
+		user.Location,
+		user.AvatarURL,
+		user.ID,
+		changedBefore,
+	).Scan(
+		&user.UsernameChangedAt,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		switch {
+		case errors.As(err, &pqErr) && pqErr.Code == "23505":
+			return ErrUsernameTaken
+		case errors.Is(err, sql.ErrNoRows):
+			return s.updateProfileRefused(ctx, user.ID)
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// updateProfileRefused tells why the profile update of userID matched no
+// row: ErrUsernameCooldown when the user exists, ErrNotFound otherwise.
+func (s *UserStore) updateProfileRefused(ctx context.Context, userID int64) error {
+	query := `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`
+
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, query, userID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return ErrUsernameCooldown
+	}
+	return ErrNotFound
+}
+
+// SetPrivate makes the user's account private or public. Making it public
+// approves the pending follow requests, since anyone can follow a public
+// account.
+func (s *UserStore) SetPrivate(ctx context.Context, userID int64, private bool) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `UPDATE users SET is_private = $1 WHERE id = $2`
+		res, err := tx.ExecContext(ctx, query, private, userID)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
+
+		if private {
+			return nil
+		}
+		return approveFollowRequests(ctx, tx, userID, nil)
+	})
+}
+
 func (s *UserStore) CreateAndInvite(ctx context.Context, user *User, token string, invitationExp time.Duration) error {
 	return withTx(s.db, ctx, func(tx *sql.Tx) error {
 
@@ -173,8 +380,22 @@ func (s *UserStore) CreateAndInvite(ctx context.Context, user *User, token strin
 	})
 }
 
-func (s *UserStore) Activate(ctx context.Context, token string) error {
+func (s *UserStore) Delete(ctx context.Context, userID int64) error {
 	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.deleteUserInvitation(ctx, tx, userID); err != nil && err != ErrNotFound {
+			return err
+		}
+		if err := s.delete(ctx, tx, userID); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// Activate activates the user invited with token and returns their ID.
+func (s *UserStore) Activate(ctx context.Context, token string) (int64, error) {
+	var userID int64
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
 		// 1. find the user that this token belongs to
 		user, err := s.getUserFromInvitation(ctx, tx, token)
 		if err != nil {
@@ -189,8 +410,58 @@ func (s *UserStore) Activate(ctx context.Context, token string) error {
 		if err := s.deleteUserInvitation(ctx, tx, user.ID); err != nil {
 			return err
 		}
+		userID = user.ID
 		return nil
 	})
+	return userID, err
+}
+
+func (s *UserStore) CreatePasswordReset(ctx context.Context, userID int64, token string, exp time.Duration) error {
+	query := `INSERT INTO password_resets (token,user_id,expiry) values ($1,$2,$3)`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(
+		ctx,
+		query,
+		token,
+		userID,
+		time.Now().Add(exp),
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ResetPassword sets a new password for the owner of token, consumes every
+// outstanding reset token of that user and revokes all of their sessions.
+// It returns the ID of the user whose password changed.
+func (s *UserStore) ResetPassword(ctx context.Context, token string, password Password) (int64, error) {
+	var userID int64
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		// 1. find the user that this token belongs to
+		id, err := s.getUserIDFromPasswordReset(ctx, tx, token)
+		if err != nil {
+			return err
+		}
+		// 2. update the password
+		if err := s.updatePassword(ctx, tx, id, password); err != nil {
+			return err
+		}
+		// 3. delete the reset tokens
+		if err := s.deletePasswordResets(ctx, tx, id); err != nil {
+			return err
+		}
+		// 4. log the user out everywhere
+		if err := revokeUserSessions(ctx, tx, id); err != nil {
+			return err
+		}
+		userID = id
+		return nil
+	})
+	return userID, err
 }
 
 func (s *UserStore) getUserFromInvitation(ctx context.Context, tx *sql.Tx, token string) (*User, error) {
@@ -266,7 +537,91 @@ func (s *UserStore) update(ctx context.Context, tx *sql.Tx, user *User) error {
 		return err
 	}
 
-	rows, _ := res.RowsAffected()
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *UserStore) getUserIDFromPasswordReset(ctx context.Context, tx *sql.Tx, token string) (int64, error) {
+	query := `SELECT user_id FROM password_resets WHERE token = $1 AND expiry > $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	hash := sha256.Sum256([]byte(token))
+	hashToken := hex.EncodeToString(hash[:])
+
+	var userID int64
+	err := tx.QueryRowContext(
+		ctx,
+		query,
+		hashToken,
+		time.Now(),
+	).Scan(&userID)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return 0, ErrNotFound
+		default:
+			return 0, err
+		}
+	}
+	return userID, nil
+}
+
+func (s *UserStore) updatePassword(ctx context.Context, tx *sql.Tx, userID int64, password Password) error {
+	query := `UPDATE users SET password = $1 WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := tx.ExecContext(ctx, query, password.hash, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *UserStore) deletePasswordResets(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `DELETE FROM password_resets WHERE user_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, userID)
+	return err
+}
+
+func (s *UserStore) delete(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `DELETE FROM users WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
 	if rows == 0 {
 		return ErrNotFound
 	}
@@ -289,7 +644,10 @@ func (s *UserStore) deleteUserInvitation(ctx context.Context, tx *sql.Tx, userID
 		return err
 	}
 
-	rows, _ := res.RowsAffected()
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
 	if rows == 0 {
 		return ErrNotFound
 	}